@@ -0,0 +1,108 @@
+package cli
+
+import (
+	"backend/service"
+	"fmt"
+	"os"
+	"strconv"
+)
+
+// Run 执行命令行子命令，返回进程退出码
+//
+//	import <文件.zip>             从zip导入文章
+//	export <文件.zip> [文章ID...]  导出文章到zip，不指定ID时导出全部
+func Run(args []string) int {
+	switch args[0] {
+	case "import":
+		if len(args) != 2 {
+			break
+		}
+		return importArticles(args[1])
+	case "export":
+		if len(args) < 2 {
+			break
+		}
+		return exportArticles(args[1], args[2:])
+	}
+
+	fmt.Fprintln(os.Stderr, "用法:")
+	fmt.Fprintln(os.Stderr, "  backend import <文件.zip>             从zip导入文章")
+	fmt.Fprintln(os.Stderr, "  backend export <文件.zip> [文章ID...]  导出文章到zip，不指定ID时导出全部")
+	return 2
+}
+
+// importArticles 从zip文件导入文章
+func importArticles(file string) int {
+	f, err := os.Open(file)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "打开文件失败: %v\n", err)
+		return 1
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "读取文件失败: %v\n", err)
+		return 1
+	}
+	result, err := service.ImportArticles(f, info.Size(), "cli")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "导入文章失败: %v\n", err)
+		return 1
+	}
+
+	for _, item := range result.Articles {
+		fmt.Printf("已导入 %s -> #%d %s\n", item.File, item.ID, item.Title)
+	}
+	for _, failure := range result.Failed {
+		fmt.Fprintf(os.Stderr, "导入失败 %s: %s\n", failure.File, failure.Error)
+	}
+	fmt.Printf("共导入%d篇文章、%d张图片，新建%d个标签，失败%d个文件\n",
+		result.Imported, result.Images, len(result.TagsCreated), len(result.Failed))
+
+	// 搜索索引与相关文章在服务进程内存中计算，通知运行中的服务同步
+	if len(result.Articles) > 0 {
+		ids := make([]uint, 0, len(result.Articles))
+		for _, item := range result.Articles {
+			ids = append(ids, item.ID)
+		}
+		if err := service.NotifyArticlesReindex(ids); err != nil {
+			fmt.Fprintf(os.Stderr, "通知服务更新搜索索引失败，请重启服务: %v\n", err)
+		}
+	}
+	if len(result.Failed) > 0 {
+		return 1
+	}
+	return 0
+}
+
+// exportArticles 导出文章到zip文件
+func exportArticles(file string, idArgs []string) int {
+	var ids []uint
+	for _, arg := range idArgs {
+		id, err := strconv.ParseUint(arg, 10, 32)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "无效的文章ID: %s\n", arg)
+			return 2
+		}
+		ids = append(ids, uint(id))
+	}
+
+	f, err := os.Create(file)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "创建文件失败: %v\n", err)
+		return 1
+	}
+	count, err := service.ExportArticles(f, ids)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(file)
+		fmt.Fprintf(os.Stderr, "导出文章失败: %v\n", err)
+		return 1
+	}
+
+	fmt.Printf("已导出%d篇文章到 %s\n", count, file)
+	return 0
+}
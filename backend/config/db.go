@@ -4,6 +4,7 @@ import (
 	"backend/entity"
 	"context"
 	"fmt"
+	"log"
 	"os"
 	"time"
 
@@ -38,11 +39,22 @@ func InitDB() {
 		&entity.OJProblem{},
 		&entity.OJTestcase{},
 		&entity.Submission{},
+		&entity.UserProblemStatus{},
+		&entity.ArticleRevision{},
+		&entity.ArticleSlugRedirect{},
+		&entity.Series{},
+		&entity.ArticleDailyStat{},
+		&entity.ArticleReferrerStat{},
 	)
 	if err != nil {
 		panic("failed to migrate database: " + err.Error())
 	}
 
+	// 补齐历史文章的发布时间
+	if err := DB.Exec("UPDATE articles SET published_at = created_at WHERE status = 'published' AND published_at IS NULL").Error; err != nil {
+		log.Printf("补齐文章发布时间失败: %v", err)
+	}
+
 	fmt.Println("Database connected successfully!")
 }
 
@@ -0,0 +1,54 @@
+package controller
+
+import (
+	"backend/service"
+	"backend/utils"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// GetArticleAnalytics 获取文章的访问统计（?from=2024-01-01&to=2024-01-31，默认最近30天）
+func GetArticleAnalytics(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.Fail(c, http.StatusBadRequest, "无效的文章ID")
+		return
+	}
+
+	now := time.Now()
+	to := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+	if toStr := c.Query("to"); toStr != "" {
+		if to, err = time.ParseInLocation("2006-01-02", toStr, time.Local); err != nil {
+			utils.Fail(c, http.StatusBadRequest, "无效的结束日期，格式应为YYYY-MM-DD")
+			return
+		}
+	}
+	from := to.AddDate(0, 0, -29)
+	if fromStr := c.Query("from"); fromStr != "" {
+		if from, err = time.ParseInLocation("2006-01-02", fromStr, time.Local); err != nil {
+			utils.Fail(c, http.StatusBadRequest, "无效的开始日期，格式应为YYYY-MM-DD")
+			return
+		}
+	}
+
+	analytics, err := service.GetArticleAnalytics(uint(id), from, to)
+	if errors.Is(err, service.ErrInvalidAnalyticsRange) {
+		utils.Fail(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		utils.Fail(c, http.StatusNotFound, "文章不存在")
+		return
+	}
+	if err != nil {
+		utils.Fail(c, http.StatusInternalServerError, "获取访问统计失败: "+err.Error())
+		return
+	}
+
+	utils.Success(c, analytics, "")
+}
@@ -0,0 +1,59 @@
+package controller
+
+import (
+	"backend/service"
+	"backend/utils"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetArticleArchive 获取按年、月统计的已发布文章数
+func GetArticleArchive(c *gin.Context) {
+	archive, err := service.GetArticleArchive()
+	if err != nil {
+		utils.Fail(c, http.StatusInternalServerError, "获取文章归档失败: "+err.Error())
+		return
+	}
+
+	utils.Success(c, archive, "")
+}
+
+// GetArchivedArticles 获取指定年份或月份发布的文章列表
+// 支持与文章列表相同的筛选、排序与分页参数，未指定sortBy时按发布时间倒序
+func GetArchivedArticles(c *gin.Context) {
+	year, err := strconv.Atoi(c.Param("year"))
+	if err != nil || year < 1970 || year > 9999 {
+		utils.Fail(c, http.StatusBadRequest, "无效的年份")
+		return
+	}
+	month := 0
+	if monthStr := c.Param("month"); monthStr != "" {
+		month, err = strconv.Atoi(monthStr)
+		if err != nil || month < 1 || month > 12 {
+			utils.Fail(c, http.StatusBadRequest, "无效的月份")
+			return
+		}
+	}
+
+	query, ok := parseArticleListQuery(c)
+	if !ok {
+		return
+	}
+	if c.Query("sortBy") == "" {
+		query.SortBy = "publishedAt"
+	}
+	from, to := service.ArchiveRange(year, month)
+	query.ArchiveFrom = &from
+	query.ArchiveTo = &to
+	query.Public = true
+
+	articles, err := service.GetArticleList(query)
+	if err != nil {
+		utils.Fail(c, http.StatusInternalServerError, "获取文章列表失败: "+err.Error())
+		return
+	}
+
+	utils.Success(c, articles, "")
+}
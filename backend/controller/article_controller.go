@@ -2,12 +2,18 @@ package controller
 
 import (
 	"backend/dto"
+	"backend/entity"
 	"backend/service"
 	"backend/utils"
+	"errors"
 	"net/http"
+	"net/url"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 )
 
 // CreateArticle 创建文章
@@ -18,17 +24,92 @@ func CreateArticle(c *gin.Context) {
 		return
 	}
 
-	article, err := service.CreateArticle(req)
+	article, err := service.CreateArticle(req, getRequestUser(c))
+	if errors.Is(err, service.ErrInvalidArticleStatus) || errors.Is(err, service.ErrInvalidPublishTime) ||
+		errors.Is(err, service.ErrInvalidSlug) {
+		utils.Fail(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	if errors.Is(err, service.ErrSlugTaken) {
+		utils.Fail(c, http.StatusConflict, err.Error())
+		return
+	}
 	if err != nil {
 		utils.Fail(c, http.StatusInternalServerError, "创建文章失败: "+err.Error())
 		return
 	}
 
+	applyArticleIncludes(c, article)
 	utils.Success(c, article, "文章创建成功")
 }
 
-// GetArticles 获取文章列表
+// GetArticles 获取已发布的文章列表
+// 支持参数：page、pageSize、tagIds（逗号分隔）、tagMode（any/all）、startDate/endDate（YYYY-MM-DD）、
+// keyword、sortBy（createdAt/updatedAt/publishedAt/views/likes）、order（asc/desc）
 func GetArticles(c *gin.Context) {
+	query, ok := parseArticleListQuery(c)
+	if !ok {
+		return
+	}
+	query.Public = true
+	query.PinnedFirst = true
+
+	articles, err := service.GetArticleList(query)
+	if err != nil {
+		utils.Fail(c, http.StatusInternalServerError, "获取文章列表失败: "+err.Error())
+		return
+	}
+
+	utils.Success(c, articles, "")
+}
+
+// AdminGetArticles 管理端获取文章列表（包含草稿、定时、归档文章，可用status筛选）
+func AdminGetArticles(c *gin.Context) {
+	query, ok := parseArticleListQuery(c)
+	if !ok {
+		return
+	}
+	query.Status = c.Query("status")
+	switch query.Status {
+	case "", entity.ArticleStatusDraft, entity.ArticleStatusPublished,
+		entity.ArticleStatusScheduled, entity.ArticleStatusArchived:
+	default:
+		utils.Fail(c, http.StatusBadRequest, service.ErrInvalidArticleStatus.Error())
+		return
+	}
+
+	articles, err := service.GetArticleList(query)
+	if err != nil {
+		utils.Fail(c, http.StatusInternalServerError, "获取文章列表失败: "+err.Error())
+		return
+	}
+
+	utils.Success(c, articles, "")
+}
+
+// AdminGetArticleByID 管理端获取文章详情（任意状态，不统计阅读量）
+func AdminGetArticleByID(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		utils.Fail(c, http.StatusBadRequest, "无效的文章ID")
+		return
+	}
+
+	article, err := service.GetArticleByID(uint(id))
+	if err != nil {
+		utils.Fail(c, http.StatusNotFound, "文章不存在")
+		return
+	}
+
+	setVersionETag(c, article.Version)
+	setLastModified(c, article.UpdatedAt)
+	applyArticleIncludes(c, article)
+	utils.Success(c, article, "")
+}
+
+// parseArticleListQuery 解析文章列表查询参数，参数无效时写入错误响应并返回false
+func parseArticleListQuery(c *gin.Context) (dto.ArticleListQuery, bool) {
 	// 获取分页参数
 	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
 	pageSize, _ := strconv.Atoi(c.DefaultQuery("pageSize", "10"))
@@ -40,13 +121,88 @@ func GetArticles(c *gin.Context) {
 		pageSize = 10
 	}
 
-	articles, err := service.GetArticleList(page, pageSize)
+	query := dto.ArticleListQuery{
+		Page:     page,
+		PageSize: pageSize,
+		TagMode:  c.DefaultQuery("tagMode", "any"),
+		Keyword:  strings.TrimSpace(c.Query("keyword")),
+		SortBy:   c.DefaultQuery("sortBy", "createdAt"),
+		Order:    c.DefaultQuery("order", "desc"),
+	}
+	if query.TagMode != "any" && query.TagMode != "all" {
+		utils.Fail(c, http.StatusBadRequest, "tagMode只能为any或all")
+		return query, false
+	}
+	if !service.IsValidArticleSort(query.SortBy) {
+		utils.Fail(c, http.StatusBadRequest, "无效的排序字段")
+		return query, false
+	}
+	if query.Order != "asc" && query.Order != "desc" {
+		utils.Fail(c, http.StatusBadRequest, "order只能为asc或desc")
+		return query, false
+	}
+
+	if tagIdsStr := c.Query("tagIds"); tagIdsStr != "" {
+		for _, idStr := range strings.Split(tagIdsStr, ",") {
+			id, err := strconv.ParseUint(strings.TrimSpace(idStr), 10, 32)
+			if err != nil {
+				utils.Fail(c, http.StatusBadRequest, "无效的标签ID: "+idStr)
+				return query, false
+			}
+			query.TagIds = append(query.TagIds, uint(id))
+		}
+	}
+
+	if startDate := c.Query("startDate"); startDate != "" {
+		t, err := time.ParseInLocation("2006-01-02", startDate, time.Local)
+		if err != nil {
+			utils.Fail(c, http.StatusBadRequest, "无效的开始日期，格式应为YYYY-MM-DD")
+			return query, false
+		}
+		query.StartDate = &t
+	}
+	if endDate := c.Query("endDate"); endDate != "" {
+		t, err := time.ParseInLocation("2006-01-02", endDate, time.Local)
+		if err != nil {
+			utils.Fail(c, http.StatusBadRequest, "无效的结束日期，格式应为YYYY-MM-DD")
+			return query, false
+		}
+		// 结束日期当天也包含在内
+		t = t.AddDate(0, 0, 1)
+		query.EndDate = &t
+	}
+
+	return query, true
+}
+
+// SearchArticles 全文搜索文章（?q=关键词&page=1&pageSize=10）
+func SearchArticles(c *gin.Context) {
+	query := strings.TrimSpace(c.Query("q"))
+	if query == "" {
+		utils.Fail(c, http.StatusBadRequest, "搜索关键词不能为空")
+		return
+	}
+	if len([]rune(query)) > 100 {
+		utils.Fail(c, http.StatusBadRequest, "搜索关键词不能超过100个字符")
+		return
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("pageSize", "10"))
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 10
+	}
+
+	result, err := service.SearchArticles(query, page, pageSize)
 	if err != nil {
-		utils.Fail(c, http.StatusInternalServerError, "获取文章列表失败: "+err.Error())
+		utils.Fail(c, http.StatusInternalServerError, "搜索文章失败: "+err.Error())
 		return
 	}
 
-	utils.Success(c, articles, "")
+	utils.Success(c, result, "")
 }
 
 // GetArticleByID 根据ID获取文章详情（带阅读量统计）
@@ -58,68 +214,201 @@ func GetArticleByID(c *gin.Context) {
 		return
 	}
 
+	respondPublicArticle(c, uint(id))
+}
+
+// GetArticleBySlug 根据slug获取文章详情，旧slug返回301重定向到当前slug
+func GetArticleBySlug(c *gin.Context) {
+	slug := c.Param("slug")
+	id, currentSlug, redirected, err := service.ResolveArticleSlug(slug)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		utils.Fail(c, http.StatusNotFound, "文章不存在")
+		return
+	}
+	if err != nil {
+		utils.Fail(c, http.StatusInternalServerError, "获取文章失败: "+err.Error())
+		return
+	}
+	if redirected {
+		// 使用相对地址，兼容部署在路径前缀之后的情况
+		location := url.PathEscape(currentSlug)
+		if c.Request.URL.RawQuery != "" {
+			location += "?" + c.Request.URL.RawQuery
+		}
+		c.Redirect(http.StatusMovedPermanently, location)
+		return
+	}
+
+	respondPublicArticle(c, id)
+}
+
+// respondPublicArticle 返回已发布文章的详情（统计阅读量，热门文章读取缓存）
+func respondPublicArticle(c *gin.Context, id uint) {
 	// 获取客户端IP
 	clientIP := c.ClientIP()
 
 	// 使用Redis服务增加阅读量（带防刷机制）
 	redisService := &service.RedisService{}
-	viewIncremented, err := redisService.IncrementArticleViews(uint(id), clientIP)
+	viewIncremented, err := redisService.IncrementArticleViews(id, clientIP)
 	if err != nil {
 		// Redis失败不影响文章获取，仅记录日志
 		utils.LogError("Redis阅读量统计失败", err)
 	}
 
-	// 首先尝试从Redis缓存获取文章内容
-	cachedContent, err := redisService.GetCachedArticleContent(uint(id))
-	if err == nil && cachedContent != "" {
-		// 缓存命中，解析JSON并更新阅读量
-		var cachedArticle dto.ArticleResponse
-		if parseErr := utils.ParseJSONString(cachedContent, &cachedArticle); parseErr == nil {
-			// 获取Redis中的最新阅读量
-			if redisViews, viewErr := redisService.GetArticleViews(uint(id)); viewErr == nil {
-				cachedArticle.Views = redisViews
-			}
+	// 优先读取缓存，未命中时从数据库获取
+	article, cacheHit, err := service.GetPublicArticle(id)
+	if err != nil {
+		// 未发布的文章对公众不可见
+		utils.Fail(c, http.StatusNotFound, "文章不存在")
+		return
+	}
 
-			// 在响应头中添加阅读量是否增加的信息
-			if viewIncremented {
-				c.Header("X-View-Incremented", "true")
-			}
-			c.Header("X-Cache-Hit", "true")
+	// 记录每日访问统计（独立访客、阅读量与来源域名）
+	if err := service.RecordArticleVisit(id, clientIP, c.Request.Referer(), viewIncremented); err != nil {
+		utils.LogError("Redis访问统计失败", err)
+	}
+
+	if cacheHit {
+		c.Header("X-Cache-Hit", "true")
+	}
+	// 在响应头中添加阅读量是否增加的信息
+	if viewIncremented {
+		c.Header("X-View-Incremented", "true")
+	}
+	// 详情中的阅读量与回应计数取自数据库（定时同步），不随每次访问变化，可按版本号做条件请求；
+	// 实时计数通过 /articles/:id/reactions 获取
+	setVersionETag(c, article.Version)
+	setLastModified(c, article.UpdatedAt)
 
-			utils.Success(c, cachedArticle, "")
-			return
+	applyArticleIncludes(c, article)
+	utils.Success(c, article, "")
+}
+
+// articleIncludes 解析include参数（如 include=html,toc），返回需要附带的可选字段
+func articleIncludes(c *gin.Context) map[string]bool {
+	includes := make(map[string]bool)
+	for _, part := range strings.Split(c.Query("include"), ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			includes[part] = true
 		}
 	}
+	return includes
+}
 
-	// 缓存未命中，从数据库获取
-	article, err := service.GetArticleByID(uint(id))
+// applyArticleIncludes 按include参数保留渲染后的HTML与目录，未请求时不返回
+func applyArticleIncludes(c *gin.Context, article *dto.ArticleResponse) {
+	includes := articleIncludes(c)
+	if !includes["html"] {
+		article.Html = ""
+	}
+	if !includes["toc"] {
+		article.Toc = nil
+	}
+}
+
+// GetRelatedArticles 获取相关文章推荐
+func GetRelatedArticles(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
 	if err != nil {
+		utils.Fail(c, http.StatusBadRequest, "无效的文章ID")
+		return
+	}
+
+	related, err := service.GetRelatedArticles(uint(id))
+	if errors.Is(err, gorm.ErrRecordNotFound) || errors.Is(err, service.ErrArticleNotPublic) {
 		utils.Fail(c, http.StatusNotFound, "文章不存在")
 		return
 	}
+	if err != nil {
+		utils.Fail(c, http.StatusInternalServerError, "获取相关文章失败: "+err.Error())
+		return
+	}
 
-	// 获取Redis中的实时阅读量
-	redisViews, err := redisService.GetArticleViews(uint(id))
-	if err == nil {
-		// 更新文章的阅读量为Redis中的值
-		article.Views = redisViews
+	utils.Success(c, related, "")
+}
+
+// GetFeaturedArticles 获取精选文章（?limit=5，最多20篇）
+func GetFeaturedArticles(c *gin.Context) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "5"))
+	if err != nil || limit < 1 || limit > 20 {
+		limit = 5
 	}
 
-	// 检查是否应该缓存这篇文章（阅读量>100）
-	shouldCache, err := redisService.ShouldCacheArticle(uint(id))
-	if err == nil && shouldCache {
-		// 将文章内容缓存到Redis
-		if err := redisService.CacheArticleContent(uint(id), utils.ToJSONString(article)); err != nil {
-			utils.LogError("缓存文章内容失败", err)
-		}
+	articles, err := service.GetFeaturedArticles(limit)
+	if err != nil {
+		utils.Fail(c, http.StatusInternalServerError, "获取精选文章失败: "+err.Error())
+		return
 	}
 
-	// 在响应头中添加阅读量是否增加的信息
-	if viewIncremented {
-		c.Header("X-View-Incremented", "true")
+	utils.Success(c, articles, "")
+}
+
+// PinArticle 置顶文章
+func PinArticle(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.Fail(c, http.StatusBadRequest, "无效的文章ID")
+		return
 	}
 
-	utils.Success(c, article, "")
+	var req dto.ArticlePinRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Fail(c, http.StatusBadRequest, "无效的请求参数: "+err.Error())
+		return
+	}
+
+	article, err := service.PinArticle(uint(id), req)
+	respondArticleDisplayChange(c, article, err, "文章已置顶")
+}
+
+// UnpinArticle 取消置顶
+func UnpinArticle(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.Fail(c, http.StatusBadRequest, "无效的文章ID")
+		return
+	}
+
+	article, err := service.UnpinArticle(uint(id))
+	respondArticleDisplayChange(c, article, err, "已取消置顶")
+}
+
+// SetArticleFeatured 设置或取消精选
+func SetArticleFeatured(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.Fail(c, http.StatusBadRequest, "无效的文章ID")
+		return
+	}
+
+	var req dto.ArticleFeatureRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Fail(c, http.StatusBadRequest, "无效的请求参数: "+err.Error())
+		return
+	}
+
+	article, err := service.SetArticleFeatured(uint(id), *req.Featured)
+	respondArticleDisplayChange(c, article, err, "精选设置已更新")
+}
+
+// respondArticleDisplayChange 输出置顶、精选等展示属性修改的结果
+func respondArticleDisplayChange(c *gin.Context, article *dto.ArticleResponse, err error, msg string) {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		utils.Fail(c, http.StatusNotFound, "文章不存在")
+		return
+	}
+	if errors.Is(err, service.ErrInvalidPinExpiry) {
+		utils.Fail(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err != nil {
+		utils.Fail(c, http.StatusInternalServerError, "更新文章失败: "+err.Error())
+		return
+	}
+
+	setVersionETag(c, article.Version)
+	applyArticleIncludes(c, article)
+	utils.Success(c, article, msg)
 }
 
 // UpdateArticle 更新文章
@@ -137,12 +426,63 @@ func UpdateArticle(c *gin.Context) {
 		return
 	}
 
-	article, err := service.UpdateArticle(uint(id), req)
+	ifMatch, ok := ifMatchVersion(c, currentArticle(uint(id)))
+	if !ok {
+		return
+	}
+	article, err := service.UpdateArticle(uint(id), req, getRequestUser(c), ifMatch)
+	respondArticleUpdate(c, uint(id), article, err)
+}
+
+// PatchArticle 修改文章部分字段（JSON Merge Patch）
+func PatchArticle(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		utils.Fail(c, http.StatusBadRequest, "无效的文章ID")
+		return
+	}
+
+	var req dto.ArticlePatchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Fail(c, http.StatusBadRequest, "无效的请求参数: "+err.Error())
+		return
+	}
+
+	ifMatch, ok := ifMatchVersion(c, currentArticle(uint(id)))
+	if !ok {
+		return
+	}
+	article, err := service.PatchArticle(uint(id), req, getRequestUser(c), ifMatch)
+	respondArticleUpdate(c, uint(id), article, err)
+}
+
+// respondArticleUpdate 返回文章更新结果，版本冲突时返回412及文章的当前内容
+func respondArticleUpdate(c *gin.Context, id uint, article *dto.ArticleResponse, err error) {
+	if errors.Is(err, service.ErrVersionConflict) {
+		respondVersionConflict(c, currentArticle(id))
+		return
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		utils.Fail(c, http.StatusNotFound, "文章不存在")
+		return
+	}
+	if errors.Is(err, service.ErrInvalidArticleStatus) || errors.Is(err, service.ErrInvalidPublishTime) ||
+		errors.Is(err, service.ErrInvalidSlug) || errors.Is(err, service.ErrPatchFieldRequired) {
+		utils.Fail(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	if errors.Is(err, service.ErrSlugTaken) {
+		utils.Fail(c, http.StatusConflict, err.Error())
+		return
+	}
 	if err != nil {
 		utils.Fail(c, http.StatusInternalServerError, "更新文章失败: "+err.Error())
 		return
 	}
 
+	setVersionETag(c, article.Version)
+	applyArticleIncludes(c, article)
 	utils.Success(c, article, "文章更新成功")
 }
 
@@ -155,7 +495,19 @@ func DeleteArticle(c *gin.Context) {
 		return
 	}
 
-	err = service.DeleteArticle(uint(id))
+	ifMatch, ok := ifMatchVersion(c, currentArticle(uint(id)))
+	if !ok {
+		return
+	}
+	err = service.DeleteArticle(uint(id), ifMatch)
+	if errors.Is(err, service.ErrVersionConflict) {
+		respondVersionConflict(c, currentArticle(uint(id)))
+		return
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		utils.Fail(c, http.StatusNotFound, "文章不存在")
+		return
+	}
 	if err != nil {
 		utils.Fail(c, http.StatusInternalServerError, "删除文章失败: "+err.Error())
 		return
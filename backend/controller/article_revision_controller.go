@@ -0,0 +1,121 @@
+package controller
+
+import (
+	"backend/service"
+	"backend/utils"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// GetArticleRevisions 获取文章的修订历史
+func GetArticleRevisions(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.Fail(c, http.StatusBadRequest, "无效的文章ID")
+		return
+	}
+
+	revisions, err := service.GetArticleRevisions(uint(id))
+	if err != nil {
+		utils.Fail(c, http.StatusInternalServerError, "获取修订历史失败: "+err.Error())
+		return
+	}
+
+	utils.Success(c, revisions, "")
+}
+
+// GetArticleRevision 获取文章指定版本的内容
+func GetArticleRevision(c *gin.Context) {
+	id, version, ok := parseRevisionParams(c)
+	if !ok {
+		return
+	}
+
+	revision, err := service.GetArticleRevision(id, version)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		utils.Fail(c, http.StatusNotFound, "修订版本不存在")
+		return
+	}
+	if err != nil {
+		utils.Fail(c, http.StatusInternalServerError, "获取修订版本失败: "+err.Error())
+		return
+	}
+
+	utils.Success(c, revision, "")
+}
+
+// DiffArticleRevisions 比较文章两个版本的差异
+func DiffArticleRevisions(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.Fail(c, http.StatusBadRequest, "无效的文章ID")
+		return
+	}
+	from, err1 := strconv.Atoi(c.Query("from"))
+	to, err2 := strconv.Atoi(c.Query("to"))
+	if err1 != nil || err2 != nil || from < 1 || to < 1 {
+		utils.Fail(c, http.StatusBadRequest, "请通过from和to指定要比较的版本号")
+		return
+	}
+
+	diff, err := service.DiffArticleRevisions(uint(id), from, to)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		utils.Fail(c, http.StatusNotFound, "修订版本不存在")
+		return
+	}
+	if err != nil {
+		utils.Fail(c, http.StatusInternalServerError, "比较修订版本失败: "+err.Error())
+		return
+	}
+
+	utils.Success(c, diff, "")
+}
+
+// RestoreArticleRevision 将文章回滚到指定版本
+func RestoreArticleRevision(c *gin.Context) {
+	id, version, ok := parseRevisionParams(c)
+	if !ok {
+		return
+	}
+
+	ifMatch, ok := ifMatchVersion(c, currentArticle(id))
+	if !ok {
+		return
+	}
+	article, err := service.RestoreArticleRevision(id, version, getRequestUser(c), ifMatch)
+	if errors.Is(err, service.ErrVersionConflict) {
+		respondVersionConflict(c, currentArticle(id))
+		return
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		utils.Fail(c, http.StatusNotFound, "修订版本不存在")
+		return
+	}
+	if err != nil {
+		utils.Fail(c, http.StatusInternalServerError, "回滚文章失败: "+err.Error())
+		return
+	}
+
+	setVersionETag(c, article.Version)
+	applyArticleIncludes(c, article)
+	utils.Success(c, article, "文章已回滚到版本"+strconv.Itoa(version))
+}
+
+// parseRevisionParams 解析路径中的文章ID与版本号，失败时直接返回400
+func parseRevisionParams(c *gin.Context) (uint, int, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.Fail(c, http.StatusBadRequest, "无效的文章ID")
+		return 0, 0, false
+	}
+	version, err := strconv.Atoi(c.Param("version"))
+	if err != nil || version < 1 {
+		utils.Fail(c, http.StatusBadRequest, "无效的版本号")
+		return 0, 0, false
+	}
+	return uint(id), version, true
+}
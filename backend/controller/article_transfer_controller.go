@@ -0,0 +1,73 @@
+package controller
+
+import (
+	"archive/zip"
+	"backend/service"
+	"backend/utils"
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ImportArticles 从zip压缩包导入文章（Markdown文件 + YAML元数据，图片放在images/目录）
+func ImportArticles(c *gin.Context) {
+	file, header, err := c.Request.FormFile("file")
+	if err != nil {
+		utils.Fail(c, http.StatusBadRequest, "没有上传文件")
+		return
+	}
+	defer file.Close()
+
+	if !strings.EqualFold(filepath.Ext(header.Filename), ".zip") {
+		utils.Fail(c, http.StatusBadRequest, "仅支持zip压缩包")
+		return
+	}
+	if maxSize := service.ArticleImportMaxSize(); header.Size > maxSize {
+		utils.Fail(c, http.StatusBadRequest, fmt.Sprintf("压缩包不能超过%dMB", maxSize>>20))
+		return
+	}
+
+	result, err := service.ImportArticles(file, header.Size, getRequestUser(c))
+	if errors.Is(err, zip.ErrFormat) {
+		utils.Fail(c, http.StatusBadRequest, "无效的zip压缩包")
+		return
+	}
+	if err != nil {
+		utils.Fail(c, http.StatusInternalServerError, "导入文章失败: "+err.Error())
+		return
+	}
+
+	utils.Success(c, result, fmt.Sprintf("成功导入%d篇文章", result.Imported))
+}
+
+// ExportArticles 将文章导出为zip压缩包，可通过ids（逗号分隔）指定文章，默认导出全部
+func ExportArticles(c *gin.Context) {
+	var ids []uint
+	if idsStr := c.Query("ids"); idsStr != "" {
+		for _, idStr := range strings.Split(idsStr, ",") {
+			id, err := strconv.ParseUint(strings.TrimSpace(idStr), 10, 32)
+			if err != nil {
+				utils.Fail(c, http.StatusBadRequest, "无效的文章ID: "+idStr)
+				return
+			}
+			ids = append(ids, uint(id))
+		}
+	}
+
+	// 先写入内存，导出失败时仍可返回JSON错误
+	var buf bytes.Buffer
+	if _, err := service.ExportArticles(&buf, ids); err != nil {
+		utils.Fail(c, http.StatusInternalServerError, "导出文章失败: "+err.Error())
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="articles-%s.zip"`, time.Now().Format("20060102")))
+	c.Data(http.StatusOK, "application/zip", buf.Bytes())
+}
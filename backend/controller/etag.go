@@ -0,0 +1,94 @@
+package controller
+
+import (
+	"backend/service"
+	"backend/utils"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// currentVersion 查询资源当前内容及版本号，用于版本冲突时返回给前端合并
+type currentVersion func() (interface{}, uint, error)
+
+// setVersionETag 在响应头中返回资源版本对应的ETag
+func setVersionETag(c *gin.Context, version uint) {
+	c.Header("ETag", utils.VersionETag(version))
+}
+
+// setLastModified 根据更新时间（2006-01-02 15:04:05，本地时间）设置Last-Modified
+func setLastModified(c *gin.Context, updatedAt string) {
+	if t, err := time.ParseInLocation("2006-01-02 15:04:05", updatedAt, time.Local); err == nil {
+		c.Header("Last-Modified", t.UTC().Format(http.TimeFormat))
+	}
+}
+
+// ifMatchVersion 读取If-Match中期望的版本号（未提供或为*时为0，不校验版本）；
+// 列表中有多个ETag时，当前版本在其中即按当前版本校验。无法匹配或格式错误时直接返回412并返回ok=false
+func ifMatchVersion(c *gin.Context, current currentVersion) (uint, bool) {
+	match, ok := utils.ParseIfMatch(c.GetHeader("If-Match"))
+	if ok && match.Any {
+		return 0, true
+	}
+	if ok && len(match.Versions) == 1 {
+		return match.Versions[0], true
+	}
+	if ok && len(match.Versions) > 1 {
+		if _, version, err := current(); err == nil && match.Matches(version) {
+			return version, true
+		}
+	}
+	respondVersionConflict(c, current)
+	return 0, false
+}
+
+// respondVersionConflict 返回412及资源的当前内容和ETag，资源不存在时返回404
+func respondVersionConflict(c *gin.Context, current currentVersion) {
+	data, version, err := current()
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		utils.Fail(c, http.StatusNotFound, "资源不存在")
+		return
+	}
+	if err != nil {
+		utils.Fail(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	setVersionETag(c, version)
+	utils.FailWithData(c, http.StatusPreconditionFailed, service.ErrVersionConflict.Error(), data)
+}
+
+// currentArticle 文章的当前内容
+func currentArticle(id uint) currentVersion {
+	return func() (interface{}, uint, error) {
+		article, err := service.GetArticleByID(id)
+		if err != nil {
+			return nil, 0, err
+		}
+		return article, article.Version, nil
+	}
+}
+
+// currentProblem OJ问题的当前内容
+func currentProblem(id uint) currentVersion {
+	return func() (interface{}, uint, error) {
+		problem, err := service.GetProblemById(id)
+		if err != nil {
+			return nil, 0, err
+		}
+		return problem, problem.Version, nil
+	}
+}
+
+// currentTag 标签的当前内容
+func currentTag(id uint) currentVersion {
+	return func() (interface{}, uint, error) {
+		tag, err := service.GetTagByID(id)
+		if err != nil {
+			return nil, 0, err
+		}
+		return tag, tag.Version, nil
+	}
+}
@@ -0,0 +1,66 @@
+package controller
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+func TestIfMatchVersion(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	current := func(version uint, err error) currentVersion {
+		return func() (interface{}, uint, error) {
+			return map[string]uint{"version": version}, version, err
+		}
+	}
+
+	tests := []struct {
+		name       string
+		header     string
+		current    currentVersion
+		wantOK     bool
+		wantVer    uint
+		wantStatus int
+		wantETag   string
+	}{
+		{"未提供", "", current(5, nil), true, 0, 0, ""},
+		{"通配符", "*", current(5, nil), true, 0, 0, ""},
+		// 单个版本交给业务层在锁内校验
+		{"单个版本", `"v4"`, current(5, nil), true, 4, 0, ""},
+		{"列表包含当前版本", `"v3", "v5"`, current(5, nil), true, 5, 0, ""},
+		{"列表不包含当前版本", `"v3", "v4"`, current(5, nil), false, 0, http.StatusPreconditionFailed, `"v5"`},
+		{"弱ETag", `W/"v5"`, current(5, nil), false, 0, http.StatusPreconditionFailed, `"v5"`},
+		{"格式错误", `v5`, current(5, nil), false, 0, http.StatusPreconditionFailed, `"v5"`},
+		{"资源不存在", `"v1", "v2"`, current(0, gorm.ErrRecordNotFound), false, 0, http.StatusNotFound, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodPut, "/", nil)
+			if tt.header != "" {
+				c.Request.Header.Set("If-Match", tt.header)
+			}
+
+			version, ok := ifMatchVersion(c, tt.current)
+			if ok != tt.wantOK || version != tt.wantVer {
+				t.Fatalf("ifMatchVersion = %d, %v, want %d, %v", version, ok, tt.wantVer, tt.wantOK)
+			}
+			if ok {
+				if c.Writer.Written() {
+					t.Errorf("通过校验时不应写入响应")
+				}
+				return
+			}
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if got := w.Header().Get("ETag"); got != tt.wantETag {
+				t.Errorf("ETag = %q, want %q", got, tt.wantETag)
+			}
+		})
+	}
+}
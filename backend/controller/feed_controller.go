@@ -0,0 +1,59 @@
+package controller
+
+import (
+	"backend/service"
+	"backend/utils"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// GetAtomFeed 获取Atom订阅源
+func GetAtomFeed(c *gin.Context) {
+	respondFeed(c, service.FeedFormatAtom, "application/atom+xml; charset=utf-8")
+}
+
+// GetRSSFeed 获取RSS订阅源
+func GetRSSFeed(c *gin.Context) {
+	respondFeed(c, service.FeedFormatRSS, "application/rss+xml; charset=utf-8")
+}
+
+// respondFeed 解析tag、content参数并输出订阅源
+func respondFeed(c *gin.Context, format, contentType string) {
+	var tagID uint64
+	if tagStr := c.Query("tag"); tagStr != "" {
+		id, err := strconv.ParseUint(tagStr, 10, 32)
+		if err != nil {
+			utils.Fail(c, http.StatusBadRequest, "无效的标签ID")
+			return
+		}
+		tagID = id
+	}
+	full := c.Query("content") == "full"
+
+	feed, err := service.GetFeed(format, uint(tagID), full)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		utils.Fail(c, http.StatusNotFound, "标签不存在")
+		return
+	}
+	if err != nil {
+		utils.Fail(c, http.StatusInternalServerError, "生成订阅源失败: "+err.Error())
+		return
+	}
+
+	c.Data(http.StatusOK, contentType, []byte(feed))
+}
+
+// GetSitemap 获取站点地图
+func GetSitemap(c *gin.Context) {
+	sitemap, err := service.GetSitemap()
+	if err != nil {
+		utils.Fail(c, http.StatusInternalServerError, "生成站点地图失败: "+err.Error())
+		return
+	}
+
+	c.Data(http.StatusOK, "application/xml; charset=utf-8", []byte(sitemap))
+}
@@ -8,7 +8,6 @@ import (
 	"net/http"
 	"os"
 	"path/filepath"
-	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -29,17 +28,7 @@ func UploadImage(c *gin.Context) {
 	}(file)
 
 	// 验证文件类型
-	allowedTypes := []string{".jpg", ".jpeg", ".png", ".gif", ".bmp", ".webp"}
-	ext := strings.ToLower(filepath.Ext(header.Filename))
-	isAllowed := false
-	for _, allowedType := range allowedTypes {
-		if ext == allowedType {
-			isAllowed = true
-			break
-		}
-	}
-
-	if !isAllowed {
+	if !utils.IsAllowedImageExt(filepath.Ext(header.Filename)) {
 		utils.Fail(c, http.StatusBadRequest, "无效的文件类型，仅允许图片文件")
 		return
 	}
@@ -4,15 +4,28 @@ import (
 	"backend/dto"
 	"backend/service"
 	"backend/utils"
+	"errors"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 )
 
-// GetAllProblems 获取所有OJ问题
+// GetAllProblems 获取所有OJ问题（?status=solved/attempted/untouched/unsolved 按当前用户做题状态筛选）
 func GetAllProblems(c *gin.Context) {
-	problems, err := service.GetAllProblems()
+	status := c.Query("status")
+	switch status {
+	case "", service.ProblemStatusSolved, service.ProblemStatusAttempted,
+		service.ProblemStatusUntouched, service.ProblemStatusUnsolved:
+	default:
+		utils.Fail(c, http.StatusBadRequest, "无效的状态筛选参数")
+		return
+	}
+
+	// 做题状态因用户而异
+	c.Header("Vary", "X-User-Id")
+	problems, err := service.GetAllProblems(getRequestUser(c), status)
 	if err != nil {
 		utils.Fail(c, http.StatusInternalServerError, "获取OJ题目失败: "+err.Error())
 		return
@@ -52,12 +65,58 @@ func UpdateProblem(c *gin.Context) {
 		return
 	}
 
-	problem, err := service.UpdateProblem(uint(problemId), req)
+	ifMatch, ok := ifMatchVersion(c, currentProblem(uint(problemId)))
+	if !ok {
+		return
+	}
+	problem, err := service.UpdateProblem(uint(problemId), req, ifMatch)
+	if errors.Is(err, service.ErrVersionConflict) {
+		respondVersionConflict(c, currentProblem(uint(problemId)))
+		return
+	}
 	if err != nil {
 		utils.Fail(c, http.StatusInternalServerError, "更新OJ题目失败: "+err.Error())
 		return
 	}
 
+	setVersionETag(c, problem.Version)
+	utils.Success(c, problem, "OJ题目更新成功")
+}
+
+// PatchProblem 修改OJ问题部分字段（JSON Merge Patch）
+func PatchProblem(c *gin.Context) {
+	idStr := c.Param("id")
+	problemId, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		utils.Fail(c, http.StatusBadRequest, "无效的问题ID")
+		return
+	}
+
+	var req dto.OJProblemPatchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Fail(c, http.StatusBadRequest, "无效的请求参数: "+err.Error())
+		return
+	}
+
+	ifMatch, ok := ifMatchVersion(c, currentProblem(uint(problemId)))
+	if !ok {
+		return
+	}
+	problem, err := service.PatchProblem(uint(problemId), req, ifMatch)
+	if errors.Is(err, service.ErrVersionConflict) {
+		respondVersionConflict(c, currentProblem(uint(problemId)))
+		return
+	}
+	if errors.Is(err, service.ErrPatchFieldRequired) {
+		utils.Fail(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err != nil {
+		utils.Fail(c, http.StatusInternalServerError, "更新OJ题目失败: "+err.Error())
+		return
+	}
+
+	setVersionETag(c, problem.Version)
 	utils.Success(c, problem, "OJ题目更新成功")
 }
 
@@ -70,7 +129,15 @@ func DeleteProblem(c *gin.Context) {
 		return
 	}
 
-	err = service.DeleteProblem(uint(problemId))
+	ifMatch, ok := ifMatchVersion(c, currentProblem(uint(problemId)))
+	if !ok {
+		return
+	}
+	err = service.DeleteProblem(uint(problemId), ifMatch)
+	if errors.Is(err, service.ErrVersionConflict) {
+		respondVersionConflict(c, currentProblem(uint(problemId)))
+		return
+	}
 	if err != nil {
 		utils.Fail(c, http.StatusInternalServerError, "删除OJ题目失败: "+err.Error())
 		return
@@ -114,23 +181,8 @@ func CreateTestcase(c *gin.Context) {
 	utils.Success(c, createdTestcases, "批量测试用例创建成功")
 }
 
-// SubmitCode 提交代码进行评测（带频率限制）
+// SubmitCode 提交代码进行评测（带频率限制，支持Idempotency-Key）
 func SubmitCode(c *gin.Context) {
-	// 获取客户端IP
-	clientIP := c.ClientIP()
-
-	// 检查提交频率限制
-	redisService := &service.RedisService{}
-	allowed, err := redisService.CheckOJSubmitRateLimit(clientIP)
-	if err != nil {
-		utils.LogError("检查OJ提交频率限制失败", err)
-		// 即使Redis失败也允许提交，不影响核心功能
-	} else if !allowed {
-		// 超过频率限制，返回429状态码
-		utils.Fail(c, http.StatusTooManyRequests, "提交过于频繁，请稍后再试。每分钟最多提交5次。")
-		return
-	}
-
 	var req dto.CodeSubmitRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		utils.Fail(c, http.StatusBadRequest, "无效的请求参数: "+err.Error())
@@ -142,20 +194,108 @@ func SubmitCode(c *gin.Context) {
 		ProblemId: uint(req.TID),
 		Code:      req.SourceCode,
 		Language:  getLanguageName(req.LanguageID), // 将语言ID转换为语言名称
+		Submitter: getRequestUser(c),
+	}
+	fingerprint := service.SubmissionFingerprint(submission)
+	redisService := &service.RedisService{}
+
+	// 幂等键：重复请求直接返回首次提交的token，不再消耗频率限制
+	idemKey := strings.TrimSpace(c.GetHeader("Idempotency-Key"))
+	if len(idemKey) > 128 {
+		utils.Fail(c, http.StatusBadRequest, "Idempotency-Key长度不能超过128")
+		return
+	}
+	if idemKey != "" {
+		token, reserved, err := redisService.ReserveIdempotencyKey(submission.Submitter, idemKey, fingerprint)
+		switch {
+		case errors.Is(err, service.ErrIdempotencyKeyReused):
+			utils.Fail(c, http.StatusUnprocessableEntity, err.Error())
+			return
+		case err != nil:
+			// Redis失败时退化为普通提交
+			utils.LogError("检查Idempotency-Key失败", err)
+			idemKey = ""
+		case !reserved && token == "":
+			utils.Fail(c, http.StatusConflict, "相同的提交正在处理中，请稍后再试")
+			return
+		case !reserved:
+			c.Header("Idempotent-Replayed", "true")
+			utils.Success(c, dto.SubmitResponse{Token: token}, "重复请求，返回首次提交结果")
+			return
+		}
+	}
+
+	// 短时间内相同代码的重复提交，直接复用之前的评测
+	if window := service.SubmitDedupWindow(); window > 0 {
+		token, err := redisService.GetDedupSubmissionToken(fingerprint)
+		if err != nil {
+			utils.LogError("检查重复提交失败", err)
+		} else if token != "" {
+			saveIdempotentSubmit(redisService, submission.Submitter, idemKey, fingerprint, token)
+			c.Header("Idempotent-Replayed", "true")
+			utils.Success(c, dto.SubmitResponse{Token: token}, "重复提交，返回之前的评测结果")
+			return
+		}
+	}
+
+	// 检查提交频率限制
+	allowed, err := redisService.CheckOJSubmitRateLimit(c.ClientIP())
+	if err != nil {
+		utils.LogError("检查OJ提交频率限制失败", err)
+		// 即使Redis失败也允许提交，不影响核心功能
+	} else if !allowed {
+		releaseIdempotentSubmit(redisService, submission.Submitter, idemKey)
+		// 超过频率限制，返回429状态码
+		utils.Fail(c, http.StatusTooManyRequests, "提交过于频繁，请稍后再试。每分钟最多提交5次。")
+		return
 	}
 
 	result, err := service.SubmitCode(submission)
+	if err != nil {
+		releaseIdempotentSubmit(redisService, submission.Submitter, idemKey)
+	}
+	if errors.Is(err, service.ErrJudgeUnavailable) {
+		utils.Fail(c, http.StatusServiceUnavailable, err.Error())
+		return
+	}
 	if err != nil {
 		utils.Fail(c, http.StatusInternalServerError, "代码提交失败: "+err.Error())
 		return
 	}
 
+	saveIdempotentSubmit(redisService, submission.Submitter, idemKey, fingerprint, result.JudgeToken)
+	if window := service.SubmitDedupWindow(); window > 0 {
+		if err := redisService.SaveDedupSubmissionToken(fingerprint, result.JudgeToken, window); err != nil {
+			utils.LogError("记录提交指纹失败", err)
+		}
+	}
+
 	// 返回前端需要的格式
 	utils.Success(c, dto.SubmitResponse{
 		Token: result.JudgeToken,
 	}, "代码提交成功")
 }
 
+// saveIdempotentSubmit 保存幂等键对应的token
+func saveIdempotentSubmit(rs *service.RedisService, submitter, idemKey, fingerprint, token string) {
+	if idemKey == "" {
+		return
+	}
+	if err := rs.SaveIdempotencyToken(submitter, idemKey, fingerprint, token, service.IdempotencyTTL()); err != nil {
+		utils.LogError("保存Idempotency-Key失败", err)
+	}
+}
+
+// releaseIdempotentSubmit 提交未成功时释放幂等键
+func releaseIdempotentSubmit(rs *service.RedisService, submitter, idemKey string) {
+	if idemKey == "" {
+		return
+	}
+	if err := rs.ReleaseIdempotencyKey(submitter, idemKey); err != nil {
+		utils.LogError("释放Idempotency-Key失败", err)
+	}
+}
+
 // getLanguageName 将语言ID转换为语言名称
 func getLanguageName(languageID int) string {
 	switch languageID {
@@ -206,6 +346,8 @@ func GetProblemByID(c *gin.Context) {
 		return
 	}
 
+	setVersionETag(c, problem.Version)
+	setLastModified(c, problem.UpdatedAt)
 	utils.Success(c, problem, "")
 }
 
@@ -243,3 +385,104 @@ func GetJudgeResult(c *gin.Context) {
 
 	utils.Success(c, submission, "获取判题结果成功")
 }
+
+// GetJudgeHealth 获取评测服务健康状态（供监控使用，熔断时返回503）
+func GetJudgeHealth(c *gin.Context) {
+	health := service.GetJudgeHealth()
+	if !health.Healthy {
+		utils.FailWithData(c, http.StatusServiceUnavailable, "评测服务不可用", health)
+		return
+	}
+
+	utils.Success(c, health, "")
+}
+
+// GetUserProblems 获取当前用户的做题记录（?status=solved 仅返回已通过的题目）
+// 提交者标识默认是客户端IP，在有用户账号之前只允许查询自己（:submitter 为 me）
+func GetUserProblems(c *gin.Context) {
+	if c.Param("submitter") != "me" {
+		utils.Fail(c, http.StatusForbidden, "只能查询自己的做题记录")
+		return
+	}
+	submitter := getRequestUser(c)
+
+	status := c.Query("status")
+	if status != "" && status != service.ProblemStatusSolved && status != service.ProblemStatusAttempted {
+		utils.Fail(c, http.StatusBadRequest, "无效的状态筛选参数")
+		return
+	}
+
+	problems, err := service.GetUserProblems(submitter, status)
+	if err != nil {
+		utils.Fail(c, http.StatusInternalServerError, "获取用户做题记录失败: "+err.Error())
+		return
+	}
+
+	utils.Success(c, problems, "")
+}
+
+// GetLeaderboard 获取排行榜（?period=all/week/month&page=1&pageSize=20）
+func GetLeaderboard(c *gin.Context) {
+	period := c.DefaultQuery("period", service.LeaderboardAll)
+	if !service.IsValidLeaderboardPeriod(period) {
+		utils.Fail(c, http.StatusBadRequest, "无效的排行榜周期")
+		return
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("pageSize", "20"))
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+
+	leaderboard, err := service.GetLeaderboard(period, page, pageSize, getRequestUser(c))
+	if err != nil {
+		utils.Fail(c, http.StatusInternalServerError, "获取排行榜失败: "+err.Error())
+		return
+	}
+
+	utils.Success(c, leaderboard, "")
+}
+
+// RebuildLeaderboard 从提交记录重建排行榜（?period=all/week/month）
+func RebuildLeaderboard(c *gin.Context) {
+	period := c.DefaultQuery("period", service.LeaderboardAll)
+	if !service.IsValidLeaderboardPeriod(period) {
+		utils.Fail(c, http.StatusBadRequest, "无效的排行榜周期")
+		return
+	}
+
+	if err := service.RebuildLeaderboard(period); err != nil {
+		utils.Fail(c, http.StatusInternalServerError, "重建排行榜失败: "+err.Error())
+		return
+	}
+
+	utils.Success(c, nil, "排行榜重建成功")
+}
+
+// GetPerformanceDistribution 获取题目通过提交的执行时间与内存分布（?language=Python）
+func GetPerformanceDistribution(c *gin.Context) {
+	idStr := c.Param("id")
+	problemId, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		utils.Fail(c, http.StatusBadRequest, "无效的问题ID")
+		return
+	}
+
+	language := c.Query("language")
+	if language == "" {
+		utils.Fail(c, http.StatusBadRequest, "language参数是必需的")
+		return
+	}
+
+	distribution, err := service.GetPerformanceDistribution(uint(problemId), language)
+	if err != nil {
+		utils.Fail(c, http.StatusInternalServerError, "获取分布失败: "+err.Error())
+		return
+	}
+
+	utils.Success(c, distribution, "")
+}
@@ -0,0 +1,33 @@
+package controller
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestGetUserProblemsOnlyMe(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/oj/users/:submitter/problems", GetUserProblems)
+
+	tests := []struct {
+		path string
+		want int
+	}{
+		{"/oj/users/192.0.2.1/problems", http.StatusForbidden},
+		{"/oj/users/alice/problems?status=solved", http.StatusForbidden},
+		{"/oj/users/ME/problems", http.StatusForbidden},
+		// me会继续校验参数，非法的状态筛选在查询数据库之前返回400
+		{"/oj/users/me/problems?status=unknown", http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.path, nil))
+		if w.Code != tt.want {
+			t.Errorf("GET %s = %d, want %d", tt.path, w.Code, tt.want)
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package controller
+
+import (
+	"backend/dto"
+	"backend/service"
+	"backend/utils"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// GetArticleReactions 获取文章各类回应计数及当前用户已做出的回应
+func GetArticleReactions(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.Fail(c, http.StatusBadRequest, "无效的文章ID")
+		return
+	}
+
+	reactions, err := service.GetArticleReactions(uint(id), getRequestUser(c))
+	respondArticleReactions(c, reactions, err, "")
+}
+
+// ReactToArticle 对文章点赞或做出其他回应（同一用户重复回应不会重复计数）
+func ReactToArticle(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.Fail(c, http.StatusBadRequest, "无效的文章ID")
+		return
+	}
+
+	reactions, err := service.ReactToArticle(uint(id), c.Param("type"), getRequestUser(c))
+	respondArticleReactions(c, reactions, err, "回应成功")
+}
+
+// UnreactToArticle 取消对文章的点赞或其他回应
+func UnreactToArticle(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.Fail(c, http.StatusBadRequest, "无效的文章ID")
+		return
+	}
+
+	reactions, err := service.UnreactToArticle(uint(id), c.Param("type"), getRequestUser(c))
+	respondArticleReactions(c, reactions, err, "已取消回应")
+}
+
+// respondArticleReactions 统一处理回应接口的错误与响应
+func respondArticleReactions(c *gin.Context, reactions *dto.ArticleReactionsResponse, err error, msg string) {
+	if errors.Is(err, service.ErrInvalidReaction) {
+		utils.Fail(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) || errors.Is(err, service.ErrArticleNotPublic) {
+		utils.Fail(c, http.StatusNotFound, "文章不存在")
+		return
+	}
+	if err != nil {
+		utils.Fail(c, http.StatusInternalServerError, "处理文章回应失败: "+err.Error())
+		return
+	}
+
+	utils.Success(c, reactions, msg)
+}
@@ -0,0 +1,27 @@
+package controller
+
+import (
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// trustUserIDHeader 是否信任X-User-Id请求头（TRUST_USER_ID_HEADER=true）
+// 该请求头可由客户端任意设置，只应在前置网关完成认证并改写该请求头时开启
+func trustUserIDHeader() bool {
+	trusted, _ := strconv.ParseBool(os.Getenv("TRUST_USER_ID_HEADER"))
+	return trusted
+}
+
+// getRequestUser 获取请求者标识：默认使用客户端IP，开启TRUST_USER_ID_HEADER后优先使用X-User-Id请求头
+func getRequestUser(c *gin.Context) string {
+	if !trustUserIDHeader() {
+		return c.ClientIP()
+	}
+	if userID := strings.TrimSpace(c.GetHeader("X-User-Id")); userID != "" && len(userID) <= 100 {
+		return userID
+	}
+	return c.ClientIP()
+}
@@ -0,0 +1,40 @@
+package controller
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestGetRequestUser(t *testing.T) {
+	tests := []struct {
+		name   string
+		trust  string
+		header string
+		want   string
+	}{
+		{"默认忽略请求头", "", "alice", "192.0.2.1"},
+		{"显式关闭", "false", "alice", "192.0.2.1"},
+		{"开启后使用请求头", "true", "alice", "alice"},
+		{"去掉首尾空白", "true", "  alice ", "alice"},
+		{"请求头为空", "true", "  ", "192.0.2.1"},
+		{"请求头过长", "true", strings.Repeat("a", 101), "192.0.2.1"},
+		{"非法配置视为关闭", "yes", "alice", "192.0.2.1"},
+	}
+	gin.SetMode(gin.TestMode)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("TRUST_USER_ID_HEADER", tt.trust)
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			c.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+			c.Request.RemoteAddr = "192.0.2.1:12345"
+			c.Request.Header.Set("X-User-Id", tt.header)
+			if got := getRequestUser(c); got != tt.want {
+				t.Errorf("getRequestUser() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
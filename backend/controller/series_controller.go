@@ -0,0 +1,118 @@
+package controller
+
+import (
+	"backend/dto"
+	"backend/service"
+	"backend/utils"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// CreateSeries 创建系列
+func CreateSeries(c *gin.Context) {
+	var req dto.SeriesCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Fail(c, http.StatusBadRequest, "无效的请求参数: "+err.Error())
+		return
+	}
+
+	series, err := service.CreateSeries(req)
+	if errors.Is(err, service.ErrSeriesArticleNotFound) {
+		utils.Fail(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err != nil {
+		utils.Fail(c, http.StatusInternalServerError, "创建系列失败: "+err.Error())
+		return
+	}
+
+	utils.Success(c, series, "系列创建成功")
+}
+
+// GetAllSeries 获取所有系列
+func GetAllSeries(c *gin.Context) {
+	seriesList, err := service.GetAllSeries()
+	if err != nil {
+		utils.Fail(c, http.StatusInternalServerError, "获取系列列表失败: "+err.Error())
+		return
+	}
+
+	utils.Success(c, seriesList, "")
+}
+
+// GetSeriesByID 获取系列详情（只包含已发布的文章）
+func GetSeriesByID(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.Fail(c, http.StatusBadRequest, "无效的系列ID")
+		return
+	}
+
+	series, err := service.GetSeriesByID(uint(id), true)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		utils.Fail(c, http.StatusNotFound, "系列不存在")
+		return
+	}
+	if err != nil {
+		utils.Fail(c, http.StatusInternalServerError, "获取系列失败: "+err.Error())
+		return
+	}
+
+	utils.Success(c, series, "")
+}
+
+// UpdateSeries 更新系列
+func UpdateSeries(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.Fail(c, http.StatusBadRequest, "无效的系列ID")
+		return
+	}
+
+	var req dto.SeriesUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Fail(c, http.StatusBadRequest, "无效的请求参数: "+err.Error())
+		return
+	}
+
+	series, err := service.UpdateSeries(uint(id), req)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		utils.Fail(c, http.StatusNotFound, "系列不存在")
+		return
+	}
+	if errors.Is(err, service.ErrSeriesArticleNotFound) || errors.Is(err, service.ErrPatchFieldRequired) {
+		utils.Fail(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err != nil {
+		utils.Fail(c, http.StatusInternalServerError, "更新系列失败: "+err.Error())
+		return
+	}
+
+	utils.Success(c, series, "系列更新成功")
+}
+
+// DeleteSeries 删除系列（文章保留）
+func DeleteSeries(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.Fail(c, http.StatusBadRequest, "无效的系列ID")
+		return
+	}
+
+	err = service.DeleteSeries(uint(id))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		utils.Fail(c, http.StatusNotFound, "系列不存在")
+		return
+	}
+	if err != nil {
+		utils.Fail(c, http.StatusInternalServerError, "删除系列失败: "+err.Error())
+		return
+	}
+
+	utils.Success(c, nil, "系列删除成功")
+}
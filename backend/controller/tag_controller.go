@@ -4,10 +4,12 @@ import (
 	"backend/dto"
 	"backend/service"
 	"backend/utils"
+	"errors"
 	"net/http"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 )
 
 // CreateTag 创建标签
@@ -52,13 +54,66 @@ func UpdateTag(c *gin.Context) {
 		utils.Fail(c, http.StatusBadRequest, err.Error())
 		return
 	}
-	_, err = service.UpdateTag(uint(id), req)
+	ifMatch, ok := ifMatchVersion(c, currentTag(uint(id)))
+	if !ok {
+		return
+	}
+	tag, err := service.UpdateTag(uint(id), req, ifMatch)
+	if errors.Is(err, service.ErrVersionConflict) {
+		respondVersionConflict(c, currentTag(uint(id)))
+		return
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		utils.Fail(c, http.StatusNotFound, "标签不存在")
+		return
+	}
 	if err != nil {
 		utils.Fail(c, http.StatusInternalServerError, err.Error())
 		return
 	}
 
-	utils.Success(c, nil, "标签更新成功")
+	setVersionETag(c, tag.Version)
+	utils.Success(c, tag, "标签更新成功")
+}
+
+// PatchTag 修改标签（JSON Merge Patch）
+func PatchTag(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		utils.Fail(c, http.StatusBadRequest, "无效的标签ID")
+		return
+	}
+
+	var req dto.TagPatchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Fail(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	ifMatch, ok := ifMatchVersion(c, currentTag(uint(id)))
+	if !ok {
+		return
+	}
+	tag, err := service.PatchTag(uint(id), req, ifMatch)
+	if errors.Is(err, service.ErrVersionConflict) {
+		respondVersionConflict(c, currentTag(uint(id)))
+		return
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		utils.Fail(c, http.StatusNotFound, "标签不存在")
+		return
+	}
+	if errors.Is(err, service.ErrPatchFieldRequired) {
+		utils.Fail(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err != nil {
+		utils.Fail(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	setVersionETag(c, tag.Version)
+	utils.Success(c, tag, "标签更新成功")
 }
 
 // DeleteTag 删除标签
@@ -70,7 +125,19 @@ func DeleteTag(c *gin.Context) {
 		return
 	}
 
-	err = service.DeleteTag(uint(id))
+	ifMatch, ok := ifMatchVersion(c, currentTag(uint(id)))
+	if !ok {
+		return
+	}
+	err = service.DeleteTag(uint(id), ifMatch)
+	if errors.Is(err, service.ErrVersionConflict) {
+		respondVersionConflict(c, currentTag(uint(id)))
+		return
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		utils.Fail(c, http.StatusNotFound, "标签不存在")
+		return
+	}
 	if err != nil {
 		utils.Fail(c, http.StatusInternalServerError, err.Error())
 		return
@@ -0,0 +1,96 @@
+package controller
+
+import (
+	"backend/service"
+	"backend/utils"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// GetTrashItems 获取回收站中指定类型的内容（articles/comments/tags/problems）
+func GetTrashItems(c *gin.Context) {
+	itemType := c.Param("type")
+	if !service.IsValidTrashType(itemType) {
+		utils.Fail(c, http.StatusBadRequest, service.ErrInvalidTrashType.Error())
+		return
+	}
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("pageSize", "20"))
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+
+	items, err := service.GetTrashItems(itemType, page, pageSize)
+	if err != nil {
+		utils.Fail(c, http.StatusInternalServerError, "获取回收站内容失败: "+err.Error())
+		return
+	}
+
+	utils.Success(c, items, "")
+}
+
+// RestoreTrashItem 从回收站恢复内容
+func RestoreTrashItem(c *gin.Context) {
+	itemType, id, ok := parseTrashParams(c)
+	if !ok {
+		return
+	}
+
+	err := service.RestoreTrashItem(itemType, id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		utils.Fail(c, http.StatusNotFound, "回收站中没有该内容")
+		return
+	}
+	if errors.Is(err, service.ErrTrashParentDeleted) {
+		utils.Fail(c, http.StatusConflict, err.Error())
+		return
+	}
+	if err != nil {
+		utils.Fail(c, http.StatusInternalServerError, "恢复失败: "+err.Error())
+		return
+	}
+
+	utils.Success(c, nil, "恢复成功")
+}
+
+// PurgeTrashItem 永久删除回收站中的内容
+func PurgeTrashItem(c *gin.Context) {
+	itemType, id, ok := parseTrashParams(c)
+	if !ok {
+		return
+	}
+
+	err := service.PurgeTrashItem(itemType, id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		utils.Fail(c, http.StatusNotFound, "回收站中没有该内容")
+		return
+	}
+	if err != nil {
+		utils.Fail(c, http.StatusInternalServerError, "永久删除失败: "+err.Error())
+		return
+	}
+
+	utils.Success(c, nil, "已永久删除")
+}
+
+// parseTrashParams 解析路径中的内容类型与ID，失败时直接返回400
+func parseTrashParams(c *gin.Context) (string, uint, bool) {
+	itemType := c.Param("type")
+	if !service.IsValidTrashType(itemType) {
+		utils.Fail(c, http.StatusBadRequest, service.ErrInvalidTrashType.Error())
+		return "", 0, false
+	}
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.Fail(c, http.StatusBadRequest, "无效的ID")
+		return "", 0, false
+	}
+	return itemType, uint(id), true
+}
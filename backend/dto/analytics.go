@@ -0,0 +1,24 @@
+package dto
+
+// ArticleAnalyticsResponse 文章在指定日期范围内的访问统计
+type ArticleAnalyticsResponse struct {
+	ArticleId  uint                   `json:"articleId"`
+	From       string                 `json:"from"`       // 开始日期（YYYY-MM-DD，含）
+	To         string                 `json:"to"`         // 结束日期（YYYY-MM-DD，含）
+	TotalViews int64                  `json:"totalViews"` // 范围内的阅读量合计（独立访客按天去重，不能跨天相加）
+	Daily      []ArticleAnalyticsDay  `json:"daily"`      // 每天一项，按日期升序，没有访问的日期为0
+	Referrers  []ArticleReferrerCount `json:"referrers"`  // 来源域名，按阅读量倒序
+}
+
+// ArticleAnalyticsDay 文章某天的访问统计
+type ArticleAnalyticsDay struct {
+	Date     string `json:"date"`
+	Views    int64  `json:"views"`
+	Visitors int64  `json:"visitors"` // 独立访客数（HyperLogLog估算，误差约0.81%）
+}
+
+// ArticleReferrerCount 来源域名的阅读量，direct表示直接访问或未携带Referer
+type ArticleReferrerCount struct {
+	Domain string `json:"domain"`
+	Views  int64  `json:"views"`
+}
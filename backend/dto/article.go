@@ -1,44 +1,219 @@
 package dto
 
+import (
+	"backend/utils"
+	"time"
+)
+
 // ArticleCreateRequest 创建文章请求
 type ArticleCreateRequest struct {
-	Title    string `json:"title" binding:"required"`
-	Content  string `json:"content" binding:"required"`
-	Summary  string `json:"summary"`
-	CoverUrl string `json:"coverUrl"`
-	TagIds   []uint `json:"tagIds"`
+	Title       string     `json:"title" binding:"required"`
+	Slug        string     `json:"slug"` // 可选，为空时由标题生成
+	Content     string     `json:"content" binding:"required"`
+	Summary     string     `json:"summary"`
+	CoverUrl    string     `json:"coverUrl"`
+	TagIds      []uint     `json:"tagIds"`
+	Status      string     `json:"status"`      // draft/published/scheduled/archived，默认published
+	PublishedAt *time.Time `json:"publishedAt"` // 定时发布时间（RFC3339），status为scheduled时必填
 }
 
-// ArticleUpdateRequest 更新文章请求
+// ArticleUpdateRequest 更新文章请求（完整替换）
 type ArticleUpdateRequest struct {
-	Title    string `json:"title"`
-	Content  string `json:"content"`
-	Summary  string `json:"summary"`
-	CoverUrl string `json:"coverUrl"`
-	TagIds   []uint `json:"tagIds"`
+	Title       string     `json:"title" binding:"required"`
+	Slug        string     `json:"slug"` // 为空时由标题生成
+	Content     string     `json:"content" binding:"required"`
+	Summary     string     `json:"summary"`     // 为空时自动生成
+	CoverUrl    string     `json:"coverUrl"`    // 为空时自动提取
+	TagIds      []uint     `json:"tagIds"`      // 为空时清空标签
+	Status      string     `json:"status"`      // 为空表示不修改
+	PublishedAt *time.Time `json:"publishedAt"` // 为空表示不修改
+}
+
+// ArticlePatchRequest 修改文章请求（JSON Merge Patch，未提供的字段保持不变）
+type ArticlePatchRequest struct {
+	Title       PatchField[string]    `json:"title"`       // 不能为null或空
+	Slug        PatchField[string]    `json:"slug"`        // null或空字符串表示由标题重新生成
+	Content     PatchField[string]    `json:"content"`     // 不能为null或空
+	Summary     PatchField[string]    `json:"summary"`     // null表示恢复自动生成，空字符串表示清空
+	CoverUrl    PatchField[string]    `json:"coverUrl"`    // null表示恢复自动提取，空字符串表示清空
+	TagIds      PatchField[[]uint]    `json:"tagIds"`      // null或空数组表示清空标签
+	Status      PatchField[string]    `json:"status"`      // 不能为null
+	PublishedAt PatchField[time.Time] `json:"publishedAt"` // null表示清除发布时间
+}
+
+// ArticlePinRequest 置顶文章请求
+type ArticlePinRequest struct {
+	PinOrder    int        `json:"pinOrder" binding:"required,min=1"` // 置顶顺序，数值小的在前
+	PinnedUntil *time.Time `json:"pinnedUntil"`                       // 置顶截止时间（RFC3339），为空表示长期置顶
+}
+
+// ArticleFeatureRequest 设置精选文章请求
+type ArticleFeatureRequest struct {
+	Featured *bool `json:"featured" binding:"required"`
+}
+
+// ArticleListQuery 文章列表查询条件
+type ArticleListQuery struct {
+	Page        int
+	PageSize    int
+	TagIds      []uint
+	TagMode     string     // any: 包含任一标签；all: 包含全部标签
+	StartDate   *time.Time // 创建时间下界（含）
+	EndDate     *time.Time // 创建时间上界（不含）
+	ArchiveFrom *time.Time // 发布时间下界（含，没有发布时间的历史文章按创建时间），用于归档
+	ArchiveTo   *time.Time // 发布时间上界（不含）
+	Keyword     string     // 匹配标题或摘要
+	SortBy      string     // createdAt/updatedAt/publishedAt/views/likes
+	Order       string     // asc/desc
+	Status      string     // 文章状态筛选，为空表示全部状态（仅管理端）
+	Public      bool       // 公开接口：只返回已发布的文章
+	PinnedFirst bool       // 置顶文章排在最前
 }
 
 // ArticleResponse 文章响应
 type ArticleResponse struct {
-	ID        uint   `json:"id"`
-	Title     string `json:"title"`
-	Content   string `json:"content"`
-	Summary   string `json:"summary"`
-	CoverUrl  string `json:"coverUrl"`
-	Views     int64  `json:"views"` // 阅读量
-	TagIds    []uint `json:"tagIds"`
-	CreatedAt string `json:"createdAt"`
-	UpdatedAt string `json:"updatedAt"`
+	ID          uint   `json:"id"`
+	Title       string `json:"title"`
+	Slug        string `json:"slug"`
+	Content     string `json:"content"`
+	Summary     string `json:"summary"`
+	CoverUrl    string `json:"coverUrl"`
+	Views       int64  `json:"views"` // 阅读量
+	Likes       int64  `json:"likes"` // 点赞数
+	WordCount   int    `json:"wordCount"`
+	ReadingTime int    `json:"readingTime"` // 预计阅读时间（分钟）
+	TagIds      []uint `json:"tagIds"`
+	Status      string `json:"status"`
+	PublishedAt string `json:"publishedAt,omitempty"`
+	Pinned      bool   `json:"pinned"` // 当前是否置顶（已过期的置顶为false）
+	PinOrder    int    `json:"pinOrder,omitempty"`
+	PinnedUntil string `json:"pinnedUntil,omitempty"`
+	Featured    bool   `json:"featured"`
+	Version     uint   `json:"version"` // 版本号，与ETag对应
+	CreatedAt   string `json:"createdAt"`
+	UpdatedAt   string `json:"updatedAt"`
+
+	Series *ArticleSeriesInfo `json:"series,omitempty"` // 所属系列，不属于任何系列时为空
+
+	Reactions map[string]int64 `json:"reactions"` // 各类回应计数
+
+	Html string           `json:"html,omitempty"` // 渲染后的HTML（需通过include=html获取）
+	Toc  []*utils.TocItem `json:"toc,omitempty"`  // 目录（需通过include=toc获取）
 }
 
 // ArticleListResponse 文章列表响应
 type ArticleListResponse struct {
+	ID          uint   `json:"id"`
+	Title       string `json:"title"`
+	Slug        string `json:"slug"`
+	Summary     string `json:"summary"`
+	CoverUrl    string `json:"coverUrl"`
+	Views       int64  `json:"views"` // 阅读量
+	Likes       int64  `json:"likes"` // 点赞数
+	WordCount   int    `json:"wordCount"`
+	ReadingTime int    `json:"readingTime"` // 预计阅读时间（分钟）
+	TagIds      []uint `json:"tagIds"`
+	Status      string `json:"status"`
+	PublishedAt string `json:"publishedAt,omitempty"`
+	Pinned      bool   `json:"pinned"` // 当前是否置顶（已过期的置顶为false）
+	PinOrder    int    `json:"pinOrder,omitempty"`
+	PinnedUntil string `json:"pinnedUntil,omitempty"`
+	Featured    bool   `json:"featured"`
+	Version     uint   `json:"version"` // 版本号，与ETag对应
+	CreatedAt   string `json:"createdAt"`
+	UpdatedAt   string `json:"updatedAt"`
+
+	Reactions map[string]int64 `json:"reactions"` // 各类回应计数
+}
+
+// ArticleSearchItem 文章搜索结果
+type ArticleSearchItem struct {
+	ArticleListResponse
+	Score          float64 `json:"score"`          // 相关度得分
+	HighlightTitle string  `json:"highlightTitle"` // 高亮后的标题（HTML，匹配处使用<mark>包裹）
+	Snippet        string  `json:"snippet"`        // 高亮后的正文片段（HTML）
+}
+
+// RelatedArticleItem 相关文章
+type RelatedArticleItem struct {
+	ArticleListResponse
+	Score float64 `json:"score"` // 相关度得分（0-1）
+}
+
+// ArticleReactionsResponse 文章回应状态
+type ArticleReactionsResponse struct {
+	ArticleId uint             `json:"articleId"`
+	Views     int64            `json:"views"`     // 实时阅读量
+	Likes     int64            `json:"likes"`     // 点赞数
+	Reactions map[string]int64 `json:"reactions"` // 各类回应计数
+	Mine      []string         `json:"mine"`      // 当前用户已做出的回应
+}
+
+// ArticleImportResult 文章导入结果
+type ArticleImportResult struct {
+	Imported    int                    `json:"imported"`    // 成功导入的文章数
+	Articles    []ArticleImportItem    `json:"articles"`    // 成功导入的文章
+	Failed      []ArticleImportFailure `json:"failed"`      // 导入失败的文件
+	TagsCreated []string               `json:"tagsCreated"` // 新建的标签
+	Images      int                    `json:"images"`      // 导入的图片数
+}
+
+// ArticleImportItem 导入成功的文章
+type ArticleImportItem struct {
+	File  string `json:"file"`
+	ID    uint   `json:"id"`
+	Title string `json:"title"`
+}
+
+// ArticleImportFailure 导入失败的文件及原因
+type ArticleImportFailure struct {
+	File  string `json:"file"`
+	Error string `json:"error"`
+}
+
+// ArchiveMonth 归档月份
+type ArchiveMonth struct {
+	Month int   `json:"month"`
+	Count int64 `json:"count"`
+}
+
+// ArchiveYear 归档年份，months按月份倒序
+type ArchiveYear struct {
+	Year   int            `json:"year"`
+	Count  int64          `json:"count"`
+	Months []ArchiveMonth `json:"months"`
+}
+
+// ArticleRevisionResponse 文章修订记录响应
+type ArticleRevisionResponse struct {
 	ID        uint   `json:"id"`
+	ArticleId uint   `json:"articleId"`
+	Version   int    `json:"version"`
 	Title     string `json:"title"`
+	Content   string `json:"content,omitempty"` // 列表中不返回正文
 	Summary   string `json:"summary"`
 	CoverUrl  string `json:"coverUrl"`
-	Views     int64  `json:"views"` // 阅读量
 	TagIds    []uint `json:"tagIds"`
+	Editor    string `json:"editor"`
 	CreatedAt string `json:"createdAt"`
-	UpdatedAt string `json:"updatedAt"`
+}
+
+// FieldChange 字段变更
+type FieldChange struct {
+	From    interface{} `json:"from"`
+	To      interface{} `json:"to"`
+	Changed bool        `json:"changed"`
+}
+
+// ArticleRevisionDiffResponse 两个修订版本之间的差异
+type ArticleRevisionDiffResponse struct {
+	From     int              `json:"from"`
+	To       int              `json:"to"`
+	Title    FieldChange      `json:"title"`
+	Summary  FieldChange      `json:"summary"`
+	CoverUrl FieldChange      `json:"coverUrl"`
+	TagIds   FieldChange      `json:"tagIds"`
+	Content  []utils.DiffLine `json:"content"` // 正文行级差异
+	Added    int              `json:"added"`   // 新增行数
+	Removed  int              `json:"removed"` // 删除行数
 }
@@ -18,6 +18,15 @@ type OJProblemUpdateRequest struct {
 	MemoryLimit int    `json:"memoryLimit"`
 }
 
+// OJProblemPatchRequest 修改OJ问题请求（JSON Merge Patch，未提供的字段保持不变）
+type OJProblemPatchRequest struct {
+	Title       PatchField[string] `json:"title"`       // 不能为null或空
+	Description PatchField[string] `json:"description"` // 不能为null或空
+	Difficulty  PatchField[string] `json:"difficulty"`  // 不能为null或空
+	TimeLimit   PatchField[int]    `json:"timeLimit"`   // null或0表示恢复默认值
+	MemoryLimit PatchField[int]    `json:"memoryLimit"` // null或0表示恢复默认值
+}
+
 // OJProblemResponse OJ问题响应
 type OJProblemResponse struct {
 	ID          uint   `json:"id"`
@@ -26,6 +35,8 @@ type OJProblemResponse struct {
 	Difficulty  string `json:"difficulty"`
 	TimeLimit   int    `json:"timeLimit"`
 	MemoryLimit int    `json:"memoryLimit"`
+	UserStatus  string `json:"userStatus,omitempty"` // 当前用户做题状态：solved/attempted/untouched
+	Version     uint   `json:"version"`              // 版本号，与ETag对应
 	CreatedAt   string `json:"createdAt"`
 	UpdatedAt   string `json:"updatedAt"`
 }
@@ -57,6 +68,7 @@ type SubmissionCreateRequest struct {
 	ProblemId uint   `json:"problemId" binding:"required"`
 	Code      string `json:"code" binding:"required"`
 	Language  string `json:"language" binding:"required"`
+	Submitter string `json:"-"` // 提交者标识，由控制器填充
 }
 
 // SubmissionResponse 提交记录响应
@@ -71,6 +83,9 @@ type SubmissionResponse struct {
 	MemoryUsage int    `json:"memoryUsage"`
 	SubmitTime  string `json:"submitTime"`
 	JudgeToken  string `json:"judgeToken"`
+
+	RuntimePercentile *float64 `json:"runtimePercentile,omitempty"` // 执行时间超过同题同语言通过提交的百分比
+	MemoryPercentile  *float64 `json:"memoryPercentile,omitempty"`  // 内存使用超过同题同语言通过提交的百分比
 }
 
 // Judge0SubmissionRequest Judge0 API请求
@@ -124,3 +139,78 @@ type CodeSubmitRequest struct {
 	SourceCode string `json:"sourceCode" binding:"required"` // 源代码
 	LanguageID int    `json:"languageId" binding:"required"` // 语言ID
 }
+
+// Judge0AboutResponse Judge0 /about 接口响应
+type Judge0AboutResponse struct {
+	Version  string `json:"version"`
+	Homepage string `json:"homepage"`
+}
+
+// Judge0WorkerStatus Judge0 /workers 接口中的队列状态
+type Judge0WorkerStatus struct {
+	Queue     string `json:"queue"`
+	Size      int    `json:"size"`      // 队列中等待的提交数
+	Available int    `json:"available"` // 可用worker数
+	Idle      int    `json:"idle"`
+	Working   int    `json:"working"`
+	Paused    int    `json:"paused"`
+	Failed    int    `json:"failed"`
+}
+
+// JudgeHealthResponse 评测服务健康状态响应
+type JudgeHealthResponse struct {
+	Healthy             bool                 `json:"healthy"`
+	BreakerState        string               `json:"breakerState"` // CLOSED/OPEN/HALF_OPEN
+	ConsecutiveFailures int                  `json:"consecutiveFailures"`
+	LastError           string               `json:"lastError,omitempty"`
+	LastCheckAt         string               `json:"lastCheckAt,omitempty"`
+	LastSuccessAt       string               `json:"lastSuccessAt,omitempty"`
+	Version             string               `json:"version,omitempty"`
+	Workers             []Judge0WorkerStatus `json:"workers,omitempty"`
+}
+
+// UserProblemStatusResponse 用户做题状态响应
+type UserProblemStatusResponse struct {
+	ProblemId     uint   `json:"problemId"`
+	Title         string `json:"title"`
+	Difficulty    string `json:"difficulty"`
+	Status        string `json:"status"` // solved/attempted
+	AttemptCount  int    `json:"attemptCount"`
+	FirstSolvedAt string `json:"firstSolvedAt,omitempty"`
+	LastSubmitAt  string `json:"lastSubmitAt"`
+}
+
+// LeaderboardEntry 排行榜条目
+type LeaderboardEntry struct {
+	Rank        int64  `json:"rank"`        // 排名，分数相同时并列
+	DisplayId   string `json:"displayId"`   // 用户的匿名标识（提交者标识的加盐摘要，不暴露IP）
+	IsMe        bool   `json:"isMe"`        // 是否为当前用户
+	Solved      int64  `json:"solved"`      // 通过题数
+	Submissions int64  `json:"submissions"` // 提交次数
+}
+
+// LeaderboardResponse 排行榜响应
+type LeaderboardResponse struct {
+	Period   string             `json:"period"` // all/week/month
+	Total    int64              `json:"total"`
+	Page     int                `json:"page"`
+	PageSize int                `json:"pageSize"`
+	List     []LeaderboardEntry `json:"list"`
+	Me       *LeaderboardEntry  `json:"me"` // 当前用户排名，未上榜时为null
+}
+
+// HistogramBucket 分布直方图的桶
+type HistogramBucket struct {
+	Value int   `json:"value"` // 执行时间(ms)或内存桶下界(KB)
+	Count int64 `json:"count"`
+}
+
+// PerformanceDistributionResponse 通过提交的执行时间与内存分布响应
+type PerformanceDistributionResponse struct {
+	ProblemId      uint              `json:"problemId"`
+	Language       string            `json:"language"`
+	Total          int64             `json:"total"`
+	MemoryBucketKB int               `json:"memoryBucketKB"`
+	Runtime        []HistogramBucket `json:"runtime"`
+	Memory         []HistogramBucket `json:"memory"`
+}
@@ -0,0 +1,9 @@
+package dto
+
+// PageResponse 分页响应
+type PageResponse struct {
+	List     interface{} `json:"list"`
+	Total    int64       `json:"total"`
+	Page     int         `json:"page"`
+	PageSize int         `json:"pageSize"`
+}
@@ -0,0 +1,25 @@
+package dto
+
+import "encoding/json"
+
+// PatchField JSON Merge Patch（RFC 7396）中的字段，区分未提供、null和具体值
+type PatchField[T any] struct {
+	Set   bool // 请求中包含该字段
+	Null  bool // 字段值为null
+	Value T
+}
+
+// UnmarshalJSON 只有请求中出现该字段时才会被调用（包括值为null时）
+func (f *PatchField[T]) UnmarshalJSON(data []byte) error {
+	f.Set = true
+	if string(data) == "null" {
+		f.Null = true
+		return nil
+	}
+	return json.Unmarshal(data, &f.Value)
+}
+
+// HasValue 请求中包含该字段且不为null
+func (f PatchField[T]) HasValue() bool {
+	return f.Set && !f.Null
+}
@@ -0,0 +1,82 @@
+package dto
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+// patchTestRequest 覆盖常见字段类型的合并补丁请求
+type patchTestRequest struct {
+	Title  PatchField[string]   `json:"title"`
+	Count  PatchField[int]      `json:"count"`
+	Tags   PatchField[[]uint]   `json:"tags"`
+	Parent PatchField[*uint]    `json:"parent"`
+	Extra  PatchField[struct{}] `json:"extra"`
+}
+
+func TestPatchFieldUnmarshalJSON(t *testing.T) {
+	one := uint(1)
+	tests := []struct {
+		name    string
+		body    string
+		want    patchTestRequest
+		wantErr bool
+	}{
+		{"未提供字段", `{}`, patchTestRequest{}, false},
+		{"null", `{"title":null,"count":null,"tags":null,"parent":null}`, patchTestRequest{
+			Title:  PatchField[string]{Set: true, Null: true},
+			Count:  PatchField[int]{Set: true, Null: true},
+			Tags:   PatchField[[]uint]{Set: true, Null: true},
+			Parent: PatchField[*uint]{Set: true, Null: true},
+		}, false},
+		{"具体值", `{"title":"Go","count":3,"tags":[1,2],"parent":1}`, patchTestRequest{
+			Title:  PatchField[string]{Set: true, Value: "Go"},
+			Count:  PatchField[int]{Set: true, Value: 3},
+			Tags:   PatchField[[]uint]{Set: true, Value: []uint{1, 2}},
+			Parent: PatchField[*uint]{Set: true, Value: &one},
+		}, false},
+		{"零值不是null", `{"title":"","count":0,"tags":[]}`, patchTestRequest{
+			Title: PatchField[string]{Set: true},
+			Count: PatchField[int]{Set: true},
+			Tags:  PatchField[[]uint]{Set: true, Value: []uint{}},
+		}, false},
+		{"null前后有空白", `{"title" :  null }`, patchTestRequest{Title: PatchField[string]{Set: true, Null: true}}, false},
+		{"对象", `{"extra":{}}`, patchTestRequest{Extra: PatchField[struct{}]{Set: true}}, false},
+		{"类型错误", `{"count":"3"}`, patchTestRequest{}, true},
+		{"字符串null不是null", `{"title":"null"}`, patchTestRequest{Title: PatchField[string]{Set: true, Value: "null"}}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got patchTestRequest
+			err := json.Unmarshal([]byte(tt.body), &got)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Unmarshal(%s) error = %v, wantErr %v", tt.body, err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Unmarshal(%s) = %+v, want %+v", tt.body, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPatchFieldHasValue(t *testing.T) {
+	tests := []struct {
+		name  string
+		field PatchField[string]
+		want  bool
+	}{
+		{"未提供", PatchField[string]{}, false},
+		{"null", PatchField[string]{Set: true, Null: true}, false},
+		{"空字符串", PatchField[string]{Set: true}, true},
+		{"有值", PatchField[string]{Set: true, Value: "a"}, true},
+	}
+	for _, tt := range tests {
+		if got := tt.field.HasValue(); got != tt.want {
+			t.Errorf("%s: HasValue() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package dto
+
+// SeriesCreateRequest 创建系列请求
+type SeriesCreateRequest struct {
+	Title       string `json:"title" binding:"required"`
+	Description string `json:"description"`
+	ArticleIds  []uint `json:"articleIds"` // 按阅读顺序排列的文章ID
+}
+
+// SeriesUpdateRequest 更新系列请求
+type SeriesUpdateRequest struct {
+	Title       *string `json:"title"`       // 不传表示不修改，不能为空
+	Description *string `json:"description"` // 不传表示不修改，传空字符串表示清空
+	ArticleIds  []uint  `json:"articleIds"`  // 不传表示不修改，传空数组表示移除所有文章
+}
+
+// SeriesResponse 系列响应
+type SeriesResponse struct {
+	ID           uint                  `json:"id"`
+	Title        string                `json:"title"`
+	Description  string                `json:"description"`
+	ArticleCount int                   `json:"articleCount"`
+	Articles     []ArticleListResponse `json:"articles,omitempty"` // 仅详情返回，按顺序排列
+	CreatedAt    string                `json:"createdAt"`
+	UpdatedAt    string                `json:"updatedAt"`
+}
+
+// SeriesNavItem 系列中相邻文章
+type SeriesNavItem struct {
+	ID    uint   `json:"id"`
+	Title string `json:"title"`
+	Slug  string `json:"slug"`
+}
+
+// ArticleSeriesInfo 文章所属系列及上一篇/下一篇
+type ArticleSeriesInfo struct {
+	ID       uint           `json:"id"`
+	Title    string         `json:"title"`
+	Position int            `json:"position"` // 文章在系列中的位置，从1开始
+	Total    int            `json:"total"`    // 系列中已发布的文章数
+	Prev     *SeriesNavItem `json:"prev"`
+	Next     *SeriesNavItem `json:"next"`
+}
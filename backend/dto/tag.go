@@ -10,10 +10,16 @@ type TagUpdateRequest struct {
 	Name string `json:"name" binding:"required"`
 }
 
+// TagPatchRequest 修改标签请求（JSON Merge Patch）
+type TagPatchRequest struct {
+	Name PatchField[string] `json:"name"` // 不能为null或空
+}
+
 // TagResponse 标签响应
 type TagResponse struct {
 	ID        uint   `json:"id"`
 	Name      string `json:"name"`
+	Version   uint   `json:"version"` // 版本号，与ETag对应
 	CreatedAt string `json:"createdAt"`
 	UpdatedAt string `json:"updatedAt"`
 }
@@ -0,0 +1,10 @@
+package dto
+
+// TrashItem 回收站中的内容
+type TrashItem struct {
+	ID        uint   `json:"id"`
+	Type      string `json:"type"`      // articles/comments/tags/problems
+	Title     string `json:"title"`     // 文章或题目标题、标签名、评论内容（作者：内容）
+	DeletedAt string `json:"deletedAt"` // 删除时间
+	PurgeAt   string `json:"purgeAt"`   // 到期后将被永久删除的时间
+}
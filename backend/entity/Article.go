@@ -1,15 +1,45 @@
 package entity
 
-import "gorm.io/gorm"
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 文章状态
+const (
+	ArticleStatusDraft     = "draft"     // 草稿
+	ArticleStatusPublished = "published" // 已发布
+	ArticleStatusScheduled = "scheduled" // 定时发布
+	ArticleStatusArchived  = "archived"  // 已归档
+)
 
 // Article 文章实体
 type Article struct {
 	gorm.Model
-	Title    string    `gorm:"size:200;not null" json:"title"`
-	Content  string    `gorm:"type:text;not null" json:"content"`
-	Summary  string    `gorm:"size:500" json:"summary"`
-	CoverUrl string    `gorm:"size:500" json:"coverUrl"`             // 封面图片URL
-	Views    int64     `gorm:"default:0" json:"views"`               // 阅读量
-	Tags     []Tag     `gorm:"many2many:article_tags;" json:"tags"`  // 多对多关系
-	Comments []Comment `gorm:"foreignKey:ArticleID" json:"comments"` // 一对多：评论
+	Title       string     `gorm:"size:200;not null" json:"title"`
+	Slug        string     `gorm:"size:100;uniqueIndex" json:"slug"` // 访问路径中的可读标识
+	SlugAuto    bool       `gorm:"default:false" json:"-"`           // slug是否由标题自动生成
+	Content     string     `gorm:"type:text;not null" json:"content"`
+	ContentHTML string     `gorm:"type:mediumtext" json:"-"` // 渲染后的HTML（保存时生成）
+	Toc         string     `gorm:"type:text" json:"-"`       // 目录（JSON，保存时生成）
+	Summary     string     `gorm:"size:500" json:"summary"`
+	CoverUrl    string     `gorm:"size:500" json:"coverUrl"`                                 // 封面图片URL
+	Views       int64      `gorm:"default:0" json:"views"`                                   // 阅读量
+	Likes       int64      `gorm:"default:0;index" json:"likes"`                             // 点赞数（由Redis定期同步）
+	Reactions   string     `gorm:"size:500" json:"-"`                                        // 各类回应计数（JSON，由Redis定期同步）
+	WordCount   int        `gorm:"default:0" json:"wordCount"`                               // 字数
+	ReadingTime int        `gorm:"default:0" json:"readingTime"`                             // 预计阅读时间（分钟）
+	SummaryAuto bool       `gorm:"default:false" json:"-"`                                   // 摘要是否自动生成
+	CoverAuto   bool       `gorm:"default:false" json:"-"`                                   // 封面是否自动提取
+	Status      string     `gorm:"size:20;not null;default:'published';index" json:"status"` // draft/published/scheduled/archived
+	PublishedAt *time.Time `gorm:"index" json:"publishedAt"`                                 // 发布时间（定时发布时为计划发布时间）
+	SeriesID    *uint      `gorm:"index" json:"seriesId"`                                    // 所属系列
+	SeriesOrder int        `gorm:"default:0" json:"seriesOrder"`                             // 在系列中的顺序
+	PinOrder    int        `gorm:"default:0;index" json:"pinOrder"`                          // 置顶顺序，0表示未置顶，数值小的在前
+	PinnedUntil *time.Time `json:"pinnedUntil"`                                              // 置顶截止时间，为空表示长期置顶
+	Featured    bool       `gorm:"default:false;index" json:"featured"`                      // 是否精选（首页轮播）
+	Version     uint       `gorm:"not null;default:1" json:"version"`                        // 版本号，每次编辑加1，用于生成ETag
+	Tags        []Tag      `gorm:"many2many:article_tags;" json:"tags"`                      // 多对多关系
+	Comments    []Comment  `gorm:"foreignKey:ArticleID" json:"comments"`                     // 一对多：评论
 }
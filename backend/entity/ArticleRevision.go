@@ -0,0 +1,18 @@
+package entity
+
+import "gorm.io/gorm"
+
+// ArticleRevision 文章修订记录（每次保存后的完整快照）
+type ArticleRevision struct {
+	gorm.Model
+	ArticleID   uint   `gorm:"not null;uniqueIndex:idx_article_version" json:"articleId"` // 关联的文章ID
+	Version     int    `gorm:"not null;uniqueIndex:idx_article_version" json:"version"`   // 修订版本号，从1开始递增
+	Title       string `gorm:"size:200;not null" json:"title"`
+	Content     string `gorm:"type:text;not null" json:"content"`
+	Summary     string `gorm:"size:500" json:"summary"`
+	CoverUrl    string `gorm:"size:500" json:"coverUrl"`
+	SummaryAuto bool   `gorm:"default:false" json:"summaryAuto"` // 摘要是否为自动生成
+	CoverAuto   bool   `gorm:"default:false" json:"coverAuto"`   // 封面是否为自动生成
+	TagIds      string `gorm:"size:500" json:"tagIds"`           // 标签ID，逗号分隔
+	Editor      string `gorm:"size:100" json:"editor"`           // 编辑者标识
+}
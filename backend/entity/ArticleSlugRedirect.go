@@ -0,0 +1,10 @@
+package entity
+
+import "gorm.io/gorm"
+
+// ArticleSlugRedirect 文章旧slug（修改标题或slug后保留，用于重定向到新地址）
+type ArticleSlugRedirect struct {
+	gorm.Model
+	Slug      string `gorm:"size:100;not null;uniqueIndex" json:"slug"` // 旧slug
+	ArticleID uint   `gorm:"not null;index" json:"articleId"`           // 关联的文章ID
+}
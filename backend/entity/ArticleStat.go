@@ -0,0 +1,21 @@
+package entity
+
+import "gorm.io/gorm"
+
+// ArticleDailyStat 文章每日访问统计（每晚由Redis汇总）
+type ArticleDailyStat struct {
+	gorm.Model
+	ArticleID uint   `gorm:"not null;uniqueIndex:idx_article_day" json:"articleId"`   // 关联的文章ID
+	Day       string `gorm:"size:10;not null;uniqueIndex:idx_article_day" json:"day"` // 日期（YYYY-MM-DD）
+	Views     int64  `gorm:"default:0" json:"views"`                                  // 当天阅读量（与总阅读量一样经过IP防刷过滤）
+	Visitors  int64  `gorm:"default:0" json:"visitors"`                               // 当天独立访客数（HyperLogLog估算）
+}
+
+// ArticleReferrerStat 文章每日访问来源统计（按来源域名）
+type ArticleReferrerStat struct {
+	gorm.Model
+	ArticleID uint   `gorm:"not null;uniqueIndex:idx_article_day_domain" json:"articleId"`       // 关联的文章ID
+	Day       string `gorm:"size:10;not null;uniqueIndex:idx_article_day_domain" json:"day"`     // 日期（YYYY-MM-DD）
+	Domain    string `gorm:"size:100;not null;uniqueIndex:idx_article_day_domain" json:"domain"` // 来源域名，direct表示直接访问
+	Views     int64  `gorm:"default:0" json:"views"`                                             // 当天来自该域名的阅读量
+}
@@ -10,6 +10,7 @@ type OJProblem struct {
 	Difficulty  string       `gorm:"size:20;not null;default:'中等'" json:"difficulty"` // 简单/中等/困难
 	TimeLimit   int          `gorm:"default:1000" json:"timeLimit"`                   // 时间限制(ms)
 	MemoryLimit int          `gorm:"default:256" json:"memoryLimit"`                  // 内存限制(MB)
+	Version     uint         `gorm:"not null;default:1" json:"version"`               // 版本号，每次编辑加1，用于生成ETag
 	Testcases   []OJTestcase `gorm:"foreignKey:ProblemID" json:"testcases"`           // 一对多：测试用例
 	Submissions []Submission `gorm:"foreignKey:ProblemID" json:"submissions"`         // 一对多：提交记录
 }
@@ -0,0 +1,11 @@
+package entity
+
+import "gorm.io/gorm"
+
+// Series 文章系列（如多篇连载教程），文章通过 Article.SeriesID 关联，按 SeriesOrder 排序
+type Series struct {
+	gorm.Model
+	Title       string    `gorm:"size:200;not null" json:"title"`
+	Description string    `gorm:"size:1000" json:"description"`
+	Articles    []Article `gorm:"foreignKey:SeriesID" json:"articles,omitempty"` // 一对多：系列中的文章
+}
@@ -17,5 +17,6 @@ type Submission struct {
 	ExecuteTime int       `gorm:"default:0" json:"executeTime"`            // 执行时间(ms)
 	MemoryUsage int       `gorm:"default:0" json:"memoryUsage"`            // 内存使用(KB)
 	SubmitTime  time.Time `gorm:"autoCreateTime" json:"submitTime"`
-	JudgeToken  string    `gorm:"size:100" json:"judgeToken"` // Judge0返回的评测令牌
+	JudgeToken  string    `gorm:"size:100" json:"judgeToken"`      // Judge0返回的评测令牌
+	Submitter   string    `gorm:"size:100;index" json:"submitter"` // 提交者标识（X-User-Id请求头，缺省为客户端IP）
 }
@@ -6,5 +6,6 @@ import "gorm.io/gorm"
 type Tag struct {
 	gorm.Model
 	Name     string    `gorm:"size:100;not null;unique" json:"name"`              // 标签名称，唯一
+	Version  uint      `gorm:"not null;default:1" json:"version"`                 // 版本号，每次编辑加1，用于生成ETag
 	Articles []Article `gorm:"many2many:article_tags;" json:"articles,omitempty"` // 多对多关系(反向)
 }
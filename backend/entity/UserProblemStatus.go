@@ -0,0 +1,18 @@
+package entity
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// UserProblemStatus 用户做题状态实体（每个用户每道题一条记录）
+type UserProblemStatus struct {
+	gorm.Model
+	Submitter     string     `gorm:"size:100;not null;uniqueIndex:idx_user_problem" json:"submitter"` // 提交者标识
+	ProblemID     uint       `gorm:"not null;uniqueIndex:idx_user_problem" json:"problemId"`          // 关联的问题ID
+	Status        string     `gorm:"size:20;not null" json:"status"`                                  // solved/attempted
+	AttemptCount  int        `gorm:"default:0" json:"attemptCount"`                                   // 已判定的提交次数
+	FirstSolvedAt *time.Time `json:"firstSolvedAt"`                                                   // 首次通过时间
+	LastSubmitAt  time.Time  `json:"lastSubmitAt"`                                                    // 最近一次判定时间
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"backend/cli"
 	"backend/config"
 	"backend/middleware"
 	"backend/router"
@@ -32,9 +33,41 @@ func main() {
 	// 初始化Redis
 	config.InitRedis()
 
+	// 命令行子命令（导入、导出文章），执行完毕后退出
+	if len(os.Args) > 1 {
+		os.Exit(cli.Run(os.Args[1:]))
+	}
+
+	// 补充历史文章的字数、阅读时间、摘要与封面
+	service.BackfillArticleMetadata()
+
+	// 为历史文章生成slug
+	service.BackfillArticleSlugs()
+
+	// 建立文章搜索索引
+	service.InitSearchIndex()
+
 	// 启动定时同步阅读量任务
 	go service.StartViewCountSyncTask()
 
+	// 启动文章定时发布任务
+	go service.StartArticlePublishTask()
+
+	// 启动相关文章计算任务
+	go service.StartRelatedArticleTask()
+
+	// 启动文章索引同步任务（接收命令行导入等其他进程的通知）
+	go service.StartArticleReindexListener()
+
+	// 启动文章访问统计汇总任务
+	go service.StartArticleAnalyticsTask()
+
+	// 启动回收站清理任务
+	go service.StartTrashPurgeTask()
+
+	// 启动Judge0健康检查任务
+	go service.StartJudgeHealthCheckTask()
+
 	// 创建Gin实例
 	r := gin.Default()
 
@@ -17,9 +17,9 @@ func SetupCORS() gin.HandlerFunc {
 	allowOrigins := strings.Split(origins, ",")
 	return cors.New(cors.Config{
 		AllowOrigins:     allowOrigins,
-		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowHeaders:     []string{"Origin", "Content-Type", "Accept", "Authorization"},
-		ExposeHeaders:    []string{"Content-Length"},
+		AllowMethods:     []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
+		AllowHeaders:     []string{"Origin", "Content-Type", "Accept", "Authorization", "Idempotency-Key", "X-User-Id", "If-Match"},
+		ExposeHeaders:    []string{"Content-Length", "Idempotent-Replayed", "ETag"},
 		AllowCredentials: true,
 		MaxAge:           12 * time.Hour,
 	})
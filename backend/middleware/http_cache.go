@@ -0,0 +1,179 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/hex"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CachePolicy 读取环境变量中的Cache-Control策略，未设置时使用默认值
+func CachePolicy(key, defaultValue string) string {
+	if value := strings.TrimSpace(os.Getenv(key)); value != "" {
+		return value
+	}
+	return defaultValue
+}
+
+// CacheControl 为成功（及304）的响应设置Cache-Control，用于静态文件
+func CacheControl(policy string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Writer = &cacheControlWriter{ResponseWriter: c.Writer, policy: policy}
+		c.Next()
+	}
+}
+
+// cacheControlWriter 在写入响应头之前按最终状态码设置Cache-Control，避免缓存错误响应
+type cacheControlWriter struct {
+	gin.ResponseWriter
+	policy string
+}
+
+func (w *cacheControlWriter) apply(code int) {
+	if w.Written() {
+		return
+	}
+	if code < http.StatusBadRequest {
+		w.Header().Set("Cache-Control", w.policy)
+	} else {
+		w.Header().Del("Cache-Control")
+	}
+}
+
+func (w *cacheControlWriter) WriteHeader(code int) {
+	w.apply(code)
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *cacheControlWriter) WriteHeaderNow() {
+	w.apply(w.Status())
+	w.ResponseWriter.WriteHeaderNow()
+}
+
+func (w *cacheControlWriter) Write(data []byte) (int, error) {
+	w.apply(w.Status())
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *cacheControlWriter) WriteString(s string) (int, error) {
+	w.apply(w.Status())
+	return w.ResponseWriter.WriteString(s)
+}
+
+// ConditionalGET 为GET请求设置Cache-Control并处理条件请求
+// 处理器未设置ETag时根据响应体生成弱ETag；If-None-Match或If-Modified-Since命中时返回304
+func ConditionalGET(policy string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.Method != http.MethodGet {
+			c.Next()
+			return
+		}
+
+		writer := &bufferedWriter{ResponseWriter: c.Writer, status: http.StatusOK}
+		c.Writer = writer
+		c.Next()
+		c.Writer = writer.ResponseWriter
+
+		header := writer.Header()
+		if writer.status != http.StatusOK {
+			writer.ResponseWriter.WriteHeader(writer.status)
+			_, _ = writer.ResponseWriter.Write(writer.body.Bytes())
+			return
+		}
+
+		if policy != "" && header.Get("Cache-Control") == "" {
+			header.Set("Cache-Control", policy)
+		}
+		etag := header.Get("ETag")
+		if etag == "" {
+			sum := sha1.Sum(writer.body.Bytes())
+			etag = `W/"` + hex.EncodeToString(sum[:10]) + `"`
+			header.Set("ETag", etag)
+		}
+
+		if notModified(c.Request, etag, header.Get("Last-Modified")) {
+			header.Del("Content-Type")
+			header.Del("Content-Length")
+			writer.ResponseWriter.WriteHeader(http.StatusNotModified)
+			writer.ResponseWriter.WriteHeaderNow()
+			return
+		}
+		writer.ResponseWriter.WriteHeader(http.StatusOK)
+		_, _ = writer.ResponseWriter.Write(writer.body.Bytes())
+	}
+}
+
+// notModified 判断条件请求是否命中：有If-None-Match时按ETag弱比较，否则比较If-Modified-Since
+func notModified(r *http.Request, etag, lastModified string) bool {
+	if inm := r.Header.Get("If-None-Match"); inm != "" {
+		for _, candidate := range strings.Split(inm, ",") {
+			candidate = strings.TrimSpace(candidate)
+			if candidate == "*" || strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(etag, "W/") {
+				return true
+			}
+		}
+		return false
+	}
+
+	ims := r.Header.Get("If-Modified-Since")
+	if ims == "" || lastModified == "" {
+		return false
+	}
+	since, err := http.ParseTime(ims)
+	if err != nil {
+		return false
+	}
+	modified, err := http.ParseTime(lastModified)
+	if err != nil {
+		return false
+	}
+	return !modified.Truncate(time.Second).After(since)
+}
+
+// bufferedWriter 暂存响应体，待处理器执行完后再决定返回完整内容还是304
+type bufferedWriter struct {
+	gin.ResponseWriter
+	status  int
+	written bool
+	body    bytes.Buffer
+}
+
+func (w *bufferedWriter) WriteHeader(code int) {
+	if code > 0 && !w.written {
+		w.status = code
+	}
+}
+
+func (w *bufferedWriter) WriteHeaderNow() {
+	w.written = true
+}
+
+func (w *bufferedWriter) Write(data []byte) (int, error) {
+	w.written = true
+	return w.body.Write(data)
+}
+
+func (w *bufferedWriter) WriteString(s string) (int, error) {
+	w.written = true
+	return w.body.WriteString(s)
+}
+
+func (w *bufferedWriter) Status() int {
+	return w.status
+}
+
+func (w *bufferedWriter) Size() int {
+	if !w.written {
+		return -1
+	}
+	return w.body.Len()
+}
+
+func (w *bufferedWriter) Written() bool {
+	return w.written
+}
@@ -0,0 +1,127 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// newCacheRouter 构造挂载ConditionalGET的路由，/item返回body的内容，/missing返回404
+func newCacheRouter(body *string, etag, lastModified string) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/item", ConditionalGET("public, no-cache"), func(c *gin.Context) {
+		if etag != "" {
+			c.Header("ETag", etag)
+		}
+		if lastModified != "" {
+			c.Header("Last-Modified", lastModified)
+		}
+		c.String(http.StatusOK, *body)
+	})
+	r.GET("/missing", ConditionalGET("public, no-cache"), func(c *gin.Context) {
+		c.String(http.StatusNotFound, "not found")
+	})
+	return r
+}
+
+func serve(r *gin.Engine, path string, header map[string]string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	for k, v := range header {
+		req.Header.Set(k, v)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestConditionalGETBodyETag(t *testing.T) {
+	body := `{"views":1}`
+	r := newCacheRouter(&body, "", "")
+
+	first := serve(r, "/item", nil)
+	etag := first.Header().Get("ETag")
+	if first.Code != http.StatusOK || first.Body.String() != body {
+		t.Fatalf("首次请求: %d %q", first.Code, first.Body.String())
+	}
+	if len(etag) < 4 || etag[:2] != "W/" {
+		t.Fatalf("未设置ETag时应生成弱ETag, got %q", etag)
+	}
+	if got := first.Header().Get("Cache-Control"); got != "public, no-cache" {
+		t.Errorf("Cache-Control = %q", got)
+	}
+
+	tests := []struct {
+		name        string
+		ifNoneMatch string
+		body        string
+		want        int
+	}{
+		{"相同内容", etag, body, http.StatusNotModified},
+		{"强比较形式", etag[2:], body, http.StatusNotModified},
+		{"列表中包含", `"other", ` + etag, body, http.StatusNotModified},
+		{"通配符", "*", body, http.StatusNotModified},
+		{"内容变化", etag, `{"views":2}`, http.StatusOK},
+		{"不匹配", `W/"other"`, body, http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body = tt.body
+			w := serve(r, "/item", map[string]string{"If-None-Match": tt.ifNoneMatch})
+			if w.Code != tt.want {
+				t.Fatalf("status = %d, want %d", w.Code, tt.want)
+			}
+			if tt.want == http.StatusNotModified && w.Body.Len() != 0 {
+				t.Errorf("304不应包含响应体, got %q", w.Body.String())
+			}
+			if tt.want == http.StatusOK && w.Body.String() != tt.body {
+				t.Errorf("body = %q, want %q", w.Body.String(), tt.body)
+			}
+		})
+	}
+}
+
+func TestConditionalGETHandlerETagAndLastModified(t *testing.T) {
+	body := "content"
+	modified := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	r := newCacheRouter(&body, `"v3"`, modified.Format(http.TimeFormat))
+
+	tests := []struct {
+		name   string
+		header map[string]string
+		want   int
+	}{
+		{"版本相同", map[string]string{"If-None-Match": `"v3"`}, http.StatusNotModified},
+		{"版本不同", map[string]string{"If-None-Match": `"v2"`}, http.StatusOK},
+		{"未修改", map[string]string{"If-Modified-Since": modified.Format(http.TimeFormat)}, http.StatusNotModified},
+		{"已修改", map[string]string{"If-Modified-Since": modified.Add(-time.Second).Format(http.TimeFormat)}, http.StatusOK},
+		// 同时提供时只比较ETag
+		{"ETag优先", map[string]string{"If-None-Match": `"v2"`, "If-Modified-Since": modified.Format(http.TimeFormat)}, http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := serve(r, "/item", tt.header)
+			if w.Code != tt.want {
+				t.Fatalf("status = %d, want %d", w.Code, tt.want)
+			}
+			if got := w.Header().Get("ETag"); got != `"v3"` {
+				t.Errorf("处理器设置的ETag不应被替换, got %q", got)
+			}
+		})
+	}
+}
+
+func TestConditionalGETSkipsErrors(t *testing.T) {
+	body := ""
+	r := newCacheRouter(&body, "", "")
+	w := serve(r, "/missing", map[string]string{"If-None-Match": "*"})
+	if w.Code != http.StatusNotFound || w.Body.String() != "not found" {
+		t.Fatalf("错误响应应原样返回, got %d %q", w.Code, w.Body.String())
+	}
+	if w.Header().Get("ETag") != "" || w.Header().Get("Cache-Control") != "" {
+		t.Errorf("错误响应不应带缓存头: %v", w.Header())
+	}
+}
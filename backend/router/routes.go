@@ -2,17 +2,31 @@ package router
 
 import (
 	"backend/controller"
+	"backend/middleware"
 
 	"github.com/gin-gonic/gin"
 )
 
 // SetupRoutes 设置所有路由
 func SetupRoutes(r *gin.Engine) {
-	// 静态文件服务 - 图片访问
-	r.Static("/images", "./images")
+	// HTTP缓存策略（Cache-Control），可通过环境变量覆盖
+	articleCache := middleware.ConditionalGET(middleware.CachePolicy("CACHE_CONTROL_ARTICLE", "public, no-cache"))
+	articleListCache := middleware.ConditionalGET(middleware.CachePolicy("CACHE_CONTROL_ARTICLE_LIST", "public, max-age=60"))
+	tagCache := middleware.ConditionalGET(middleware.CachePolicy("CACHE_CONTROL_TAGS", "public, max-age=60"))
+	problemCache := middleware.ConditionalGET(middleware.CachePolicy("CACHE_CONTROL_PROBLEMS", "private, no-cache"))
+
+	// 静态文件服务 - 图片访问（上传的文件名带时间戳，内容不会变化）
+	images := r.Group("/images", middleware.CacheControl(
+		middleware.CachePolicy("CACHE_CONTROL_IMAGES", "public, max-age=31536000, immutable")))
+	images.Static("/", "./images")
 	// 图片上传接口 (前端使用 /img)
 	r.POST("/img", controller.UploadImage)
 
+	// 订阅源与站点地图（?tag=标签ID 订阅单个标签，?content=full 输出全文）
+	r.GET("/feed.xml", controller.GetAtomFeed)
+	r.GET("/rss.xml", controller.GetRSSFeed)
+	r.GET("/sitemap.xml", controller.GetSitemap)
+
 	//// API路由组
 	//api := r.Group("/api")
 	//{
@@ -30,12 +44,64 @@ func SetupRoutes(r *gin.Engine) {
 	articles := r.Group("/articles")
 	{
 		articles.POST("", controller.CreateArticle)
-		articles.GET("", controller.GetArticles)
-		articles.GET("/:id", controller.GetArticleByID)
+		articles.GET("", articleListCache, controller.GetArticles)
+		articles.GET("/search", controller.SearchArticles)
+		articles.GET("/featured", articleListCache, controller.GetFeaturedArticles)
+		articles.GET("/archive", articleListCache, controller.GetArticleArchive)
+		articles.GET("/archive/:year", articleListCache, controller.GetArchivedArticles)
+		articles.GET("/archive/:year/:month", articleListCache, controller.GetArchivedArticles)
+		articles.GET("/slug/:slug", articleCache, controller.GetArticleBySlug)
+		articles.GET("/:id", articleCache, controller.GetArticleByID)
+		articles.GET("/:id/related", controller.GetRelatedArticles)
+		articles.GET("/:id/reactions", controller.GetArticleReactions)
+		articles.PUT("/:id/reactions/:type", controller.ReactToArticle)
+		articles.DELETE("/:id/reactions/:type", controller.UnreactToArticle)
 		articles.PUT("/:id", controller.UpdateArticle)
+		articles.PATCH("/:id", controller.PatchArticle)
 		articles.DELETE("/:id", controller.DeleteArticle)
 	}
 
+	// 管理端文章路由（包含未发布的文章）
+	adminArticles := r.Group("/admin/articles")
+	{
+		adminArticles.GET("", controller.AdminGetArticles)
+		adminArticles.GET("/:id", controller.AdminGetArticleByID)
+		adminArticles.GET("/:id/analytics", controller.GetArticleAnalytics)
+
+		// 置顶与精选
+		adminArticles.PUT("/:id/pin", controller.PinArticle)
+		adminArticles.DELETE("/:id/pin", controller.UnpinArticle)
+		adminArticles.PUT("/:id/featured", controller.SetArticleFeatured)
+
+		// 修订历史
+		adminArticles.GET("/:id/revisions", controller.GetArticleRevisions)
+		adminArticles.GET("/:id/revisions/diff", controller.DiffArticleRevisions)
+		adminArticles.GET("/:id/revisions/:version", controller.GetArticleRevision)
+		adminArticles.POST("/:id/revisions/:version/restore", controller.RestoreArticleRevision)
+
+		// Markdown导入导出
+		adminArticles.POST("/import", controller.ImportArticles)
+		adminArticles.GET("/export", controller.ExportArticles)
+	}
+
+	// 回收站路由（软删除的文章、评论、标签、OJ题目）
+	trash := r.Group("/admin/trash")
+	{
+		trash.GET("/:type", controller.GetTrashItems)
+		trash.POST("/:type/:id/restore", controller.RestoreTrashItem)
+		trash.DELETE("/:type/:id", controller.PurgeTrashItem)
+	}
+
+	// 文章系列路由
+	series := r.Group("/series")
+	{
+		series.POST("", controller.CreateSeries)
+		series.GET("", controller.GetAllSeries)
+		series.GET("/:id", controller.GetSeriesByID)
+		series.PUT("/:id", controller.UpdateSeries)
+		series.DELETE("/:id", controller.DeleteSeries)
+	}
+
 	// 评论相关路由
 	comments := r.Group("/comments")
 	{
@@ -47,21 +113,32 @@ func SetupRoutes(r *gin.Engine) {
 	tags := r.Group("/tags")
 	{
 		tags.POST("", controller.CreateTag)
-		tags.GET("", controller.GetAllTags)
+		tags.GET("", tagCache, controller.GetAllTags)
 		tags.PUT("/:id", controller.UpdateTag)
+		tags.PATCH("/:id", controller.PatchTag)
 		tags.DELETE("/:id", controller.DeleteTag)
 	}
 	// OJ相关路由
 	oj := r.Group("/oj")
 	{
-		oj.GET("/problems", controller.GetAllProblems)
-		oj.GET("/problems/:id", controller.GetProblemByID) // 新增：根据ID获取题目
+		oj.GET("/problems", problemCache, controller.GetAllProblems)
+		oj.GET("/problems/:id", problemCache, controller.GetProblemByID) // 新增：根据ID获取题目
+		oj.GET("/problems/:id/distribution", problemCache, controller.GetPerformanceDistribution)
 		oj.POST("/problem", controller.CreateProblem)
 		oj.PUT("/problem/:id", controller.UpdateProblem) // 新增：更新题目
+		oj.PATCH("/problem/:id", controller.PatchProblem)
 		oj.DELETE("/problem/:id", controller.DeleteProblem)
 		oj.POST("/testcase/:problem_id", controller.CreateTestcase)
 		oj.GET("/testcase/:problem_id", controller.GetTestcases) // 新增：获取测试用例
 		oj.POST("/judge", controller.SubmitCode)                 // 前端使用 /oj/judge
 		oj.GET("/judge", controller.GetJudgeResult)              // 前端使用 /oj/judge?token=xxx
+		oj.GET("/judge/health", controller.GetJudgeHealth)       // 评测服务健康状态
+
+		// 用户做题记录，:submitter 为 me 时表示当前用户
+		oj.GET("/users/:submitter/problems", controller.GetUserProblems)
+
+		// 排行榜
+		oj.GET("/leaderboard", controller.GetLeaderboard)
+		oj.POST("/leaderboard/rebuild", controller.RebuildLeaderboard)
 	}
 }
@@ -0,0 +1,236 @@
+package service
+
+import (
+	"backend/config"
+	"backend/dto"
+	"backend/entity"
+	"fmt"
+	"log"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	// articleStatsDayLayout 访问统计的日期格式
+	articleStatsDayLayout = "2006-01-02"
+	// articleStatsRedisDays 每日访问统计在Redis中保留的天数（含当天），这几天内以Redis中的数据为准
+	articleStatsRedisDays = 3
+	// articleAnalyticsMaxDays 单次查询的最大天数
+	articleAnalyticsMaxDays = 366
+	// referrerDirect 直接访问（未携带Referer或无法解析）
+	referrerDirect = "direct"
+	// referrerOther 域名过长等无法保存的来源
+	referrerOther = "other"
+)
+
+// ErrInvalidAnalyticsRange 访问统计的日期范围无效
+var ErrInvalidAnalyticsRange = fmt.Errorf("日期范围无效：开始日期不能晚于结束日期，且最多查询%d天", articleAnalyticsMaxDays)
+
+// articleDayStats 文章某天在Redis中的访问统计
+type articleDayStats struct {
+	Views     int64
+	Visitors  int64
+	Referrers map[string]int64
+}
+
+// referrerDomain 从Referer中提取来源域名（小写并去掉www.前缀）
+func referrerDomain(referer string) string {
+	u, err := url.Parse(strings.TrimSpace(referer))
+	if err != nil || u.Hostname() == "" {
+		return referrerDirect
+	}
+	domain := strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+	if len(domain) > 100 {
+		return referrerOther
+	}
+	return domain
+}
+
+// RecordArticleVisit 记录文章的当天访问：独立访客、阅读量和来源域名，counted表示本次访问是否计入阅读量
+func RecordArticleVisit(articleID uint, clientIP, referer string, counted bool) error {
+	redisService := &RedisService{}
+	day := time.Now().Format(articleStatsDayLayout)
+	return redisService.RecordArticleVisit(articleID, day, clientIP, referrerDomain(referer), counted)
+}
+
+// StartArticleAnalyticsTask 启动文章访问统计汇总任务：每天00:10把前一天Redis中的统计写入数据库
+func StartArticleAnalyticsTask() {
+	log.Println("启动文章访问统计汇总任务，每天00:10汇总前一天的数据")
+
+	// 启动时补充汇总Redis中仍保留的前几天，避免停机期间错过汇总
+	now := time.Now()
+	for i := articleStatsRedisDays - 1; i >= 1; i-- {
+		day := now.AddDate(0, 0, -i).Format(articleStatsDayLayout)
+		if err := RollupArticleAnalytics(day); err != nil {
+			log.Printf("汇总%s的文章访问统计失败: %v", day, err)
+		}
+	}
+
+	for {
+		now := time.Now()
+		next := time.Date(now.Year(), now.Month(), now.Day(), 0, 10, 0, 0, now.Location())
+		if !next.After(now) {
+			next = next.AddDate(0, 0, 1)
+		}
+		time.Sleep(time.Until(next))
+
+		day := next.AddDate(0, 0, -1).Format(articleStatsDayLayout)
+		if err := RollupArticleAnalytics(day); err != nil {
+			log.Printf("汇总%s的文章访问统计失败: %v", day, err)
+		}
+	}
+}
+
+// RollupArticleAnalytics 把指定日期Redis中的文章访问统计写入数据库（重复执行会覆盖为最新的值）
+func RollupArticleAnalytics(day string) error {
+	iter := config.RedisClient.Scan(ctx, 0, "article:stats:"+day+":uv:*", 0).Iterator()
+
+	var ids []uint
+	for iter.Next(ctx) {
+		key := iter.Val()
+		id, err := strconv.ParseUint(key[strings.LastIndex(key, ":")+1:], 10, 32)
+		if err != nil {
+			log.Printf("解析文章ID失败: %s, %v", key, err)
+			continue
+		}
+		ids = append(ids, uint(id))
+	}
+	if err := iter.Err(); err != nil {
+		return fmt.Errorf("扫描Redis键失败: %v", err)
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+
+	// 已永久删除的文章不再汇总（回收站中的文章仍保留统计）
+	var existing []uint
+	if err := config.DB.Unscoped().Model(&entity.Article{}).Where("id IN ?", ids).Pluck("id", &existing).Error; err != nil {
+		return err
+	}
+	for _, id := range existing {
+		if err := saveArticleDayStats(id, day); err != nil {
+			log.Printf("汇总文章 %d 在%s的访问统计失败: %v", id, day, err)
+		}
+	}
+	log.Printf("已汇总%s的文章访问统计，共%d篇文章", day, len(existing))
+	return nil
+}
+
+// saveArticleDayStats 把文章某天在Redis中的统计写入数据库
+func saveArticleDayStats(articleID uint, day string) error {
+	redisService := &RedisService{}
+	stats, err := redisService.GetArticleDayStats(articleID, day)
+	if err != nil || stats == nil {
+		return err
+	}
+
+	return config.DB.Transaction(func(tx *gorm.DB) error {
+		daily := entity.ArticleDailyStat{ArticleID: articleID, Day: day, Views: stats.Views, Visitors: stats.Visitors}
+		if err := tx.Clauses(clause.OnConflict{
+			DoUpdates: clause.AssignmentColumns([]string{"views", "visitors", "updated_at"}),
+		}).Create(&daily).Error; err != nil {
+			return err
+		}
+
+		for domain, views := range stats.Referrers {
+			referrer := entity.ArticleReferrerStat{ArticleID: articleID, Day: day, Domain: domain, Views: views}
+			if err := tx.Clauses(clause.OnConflict{
+				DoUpdates: clause.AssignmentColumns([]string{"views", "updated_at"}),
+			}).Create(&referrer).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// GetArticleAnalytics 获取文章在日期范围内（含首尾）的每日阅读量、独立访客和来源域名
+// 最近几天的数据直接读取Redis，更早的数据读取每晚汇总的结果
+func GetArticleAnalytics(articleID uint, from, to time.Time) (*dto.ArticleAnalyticsResponse, error) {
+	if to.Before(from) || to.Sub(from) >= articleAnalyticsMaxDays*24*time.Hour {
+		return nil, ErrInvalidAnalyticsRange
+	}
+	if err := config.DB.Select("id").First(&entity.Article{}, articleID).Error; err != nil {
+		return nil, err
+	}
+
+	fromDay, toDay := from.Format(articleStatsDayLayout), to.Format(articleStatsDayLayout)
+	var dailyStats []entity.ArticleDailyStat
+	if err := config.DB.Where("article_id = ? AND day BETWEEN ? AND ?", articleID, fromDay, toDay).
+		Find(&dailyStats).Error; err != nil {
+		return nil, err
+	}
+	var referrerStats []entity.ArticleReferrerStat
+	if err := config.DB.Where("article_id = ? AND day BETWEEN ? AND ?", articleID, fromDay, toDay).
+		Find(&referrerStats).Error; err != nil {
+		return nil, err
+	}
+
+	days := make(map[string]dto.ArticleAnalyticsDay, len(dailyStats))
+	for _, stat := range dailyStats {
+		days[stat.Day] = dto.ArticleAnalyticsDay{Date: stat.Day, Views: stat.Views, Visitors: stat.Visitors}
+	}
+
+	// 最近几天以Redis为准（当天尚未汇总，汇总之后的访问也只在Redis中）
+	referrers := make(map[string]int64)
+	live := make(map[string]bool)
+	redisService := &RedisService{}
+	today := time.Now()
+	for i := 0; i < articleStatsRedisDays; i++ {
+		day := today.AddDate(0, 0, -i).Format(articleStatsDayLayout)
+		if day < fromDay || day > toDay {
+			continue
+		}
+		stats, err := redisService.GetArticleDayStats(articleID, day)
+		if err != nil {
+			log.Printf("获取文章 %d 在%s的访问统计失败: %v", articleID, day, err)
+			continue
+		}
+		if stats == nil {
+			continue
+		}
+		live[day] = true
+		days[day] = dto.ArticleAnalyticsDay{Date: day, Views: stats.Views, Visitors: stats.Visitors}
+		for domain, views := range stats.Referrers {
+			referrers[domain] += views
+		}
+	}
+	for _, stat := range referrerStats {
+		if !live[stat.Day] {
+			referrers[stat.Domain] += stat.Views
+		}
+	}
+
+	response := &dto.ArticleAnalyticsResponse{
+		ArticleId: articleID,
+		From:      fromDay,
+		To:        toDay,
+		Daily:     []dto.ArticleAnalyticsDay{},
+		Referrers: make([]dto.ArticleReferrerCount, 0, len(referrers)),
+	}
+	for date := from; !date.After(to); date = date.AddDate(0, 0, 1) {
+		day := date.Format(articleStatsDayLayout)
+		stat, ok := days[day]
+		if !ok {
+			stat = dto.ArticleAnalyticsDay{Date: day}
+		}
+		response.TotalViews += stat.Views
+		response.Daily = append(response.Daily, stat)
+	}
+	for domain, views := range referrers {
+		response.Referrers = append(response.Referrers, dto.ArticleReferrerCount{Domain: domain, Views: views})
+	}
+	sort.Slice(response.Referrers, func(i, j int) bool {
+		if response.Referrers[i].Views != response.Referrers[j].Views {
+			return response.Referrers[i].Views > response.Referrers[j].Views
+		}
+		return response.Referrers[i].Domain < response.Referrers[j].Domain
+	})
+	return response, nil
+}
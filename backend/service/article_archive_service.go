@@ -0,0 +1,63 @@
+package service
+
+import (
+	"backend/config"
+	"backend/dto"
+	"backend/entity"
+	"fmt"
+	"time"
+)
+
+// archiveDateExpr 归档使用的日期：发布时间，没有发布时间的历史文章使用创建时间
+const archiveDateExpr = "COALESCE(published_at, created_at)"
+
+// archiveCacheTTL 归档统计缓存时间（文章变化时通过版本号立即失效）
+const archiveCacheTTL = time.Hour
+
+// GetArticleArchive 按年、月统计已发布的文章数，年份与月份均倒序（结果缓存在Redis中）
+func GetArticleArchive() ([]dto.ArchiveYear, error) {
+	archive := make([]dto.ArchiveYear, 0)
+	key := fmt.Sprintf(ArchiveCountKey, cacheVersion(cacheVersionArticles))
+	if err := loadWithCache(key, archiveCacheTTL, &archive, func() (interface{}, error) {
+		return queryArticleArchive()
+	}); err != nil {
+		return nil, err
+	}
+	return archive, nil
+}
+
+// queryArticleArchive 使用分组查询统计每月的已发布文章数
+func queryArticleArchive() ([]dto.ArchiveYear, error) {
+	var rows []struct {
+		Year  int
+		Month int
+		Count int64
+	}
+	if err := config.DB.Model(&entity.Article{}).
+		Select(fmt.Sprintf("YEAR(%s) AS year, MONTH(%s) AS month, COUNT(*) AS count", archiveDateExpr, archiveDateExpr)).
+		Where("status = ?", entity.ArticleStatusPublished).
+		Group("year, month").Order("year desc, month desc").Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	archive := make([]dto.ArchiveYear, 0)
+	for _, row := range rows {
+		if len(archive) == 0 || archive[len(archive)-1].Year != row.Year {
+			archive = append(archive, dto.ArchiveYear{Year: row.Year, Months: []dto.ArchiveMonth{}})
+		}
+		year := &archive[len(archive)-1]
+		year.Count += row.Count
+		year.Months = append(year.Months, dto.ArchiveMonth{Month: row.Month, Count: row.Count})
+	}
+	return archive, nil
+}
+
+// ArchiveRange 归档的时间范围，month为0时表示整年
+func ArchiveRange(year, month int) (from, to time.Time) {
+	if month == 0 {
+		from = time.Date(year, time.January, 1, 0, 0, 0, 0, time.Local)
+		return from, from.AddDate(1, 0, 0)
+	}
+	from = time.Date(year, time.Month(month), 1, 0, 0, 0, 0, time.Local)
+	return from, from.AddDate(0, 1, 0)
+}
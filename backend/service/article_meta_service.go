@@ -0,0 +1,85 @@
+package service
+
+import (
+	"backend/config"
+	"backend/entity"
+	"backend/utils"
+	"log"
+	"math"
+)
+
+// 阅读速度：中文按字、英文按单词计算
+const (
+	cjkCharsPerMinute   = 300
+	latinWordsPerMinute = 200
+	articleSummaryLimit = 500 // 与数据库字段长度一致
+	articleCoverLimit   = 500
+)
+
+// articleSummaryLength 自动摘要长度（字符数）
+func articleSummaryLength() int {
+	return minInt(getEnvInt("ARTICLE_SUMMARY_LENGTH", 150), articleSummaryLimit-1)
+}
+
+// applyArticleMetadata 计算字数与阅读时间；摘要、封面标记为自动生成时根据正文重新生成（作者清空的保持为空）
+func applyArticleMetadata(article *entity.Article) {
+	cjk, words := utils.CountWords(utils.StripMarkdown(article.Content))
+	article.WordCount = cjk + words
+	article.ReadingTime = readingMinutes(cjk, words)
+
+	if article.SummaryAuto {
+		article.Summary = utils.Summarize(article.Content, articleSummaryLength())
+		article.SummaryAuto = true
+	}
+	if article.CoverAuto {
+		cover := utils.FirstImageURL(article.Content)
+		if len(cover) > articleCoverLimit {
+			cover = ""
+		}
+		article.CoverUrl = cover
+		article.CoverAuto = true
+	}
+}
+
+// readingMinutes 预计阅读时间（分钟），有内容时至少为1分钟
+func readingMinutes(cjk, words int) int {
+	if cjk == 0 && words == 0 {
+		return 0
+	}
+	minutes := float64(cjk)/cjkCharsPerMinute + float64(words)/latinWordsPerMinute
+	return maxInt(1, int(math.Ceil(minutes)))
+}
+
+// BackfillArticleMetadata 为历史文章补充字数、阅读时间以及缺失的摘要和封面（不修改更新时间）
+func BackfillArticleMetadata() {
+	var articles []entity.Article
+	if err := config.DB.Where("word_count = 0 AND content <> ''").Find(&articles).Error; err != nil {
+		log.Printf("查询待补充元数据的文章失败: %v", err)
+		return
+	}
+
+	for _, article := range articles {
+		// 历史文章没有记录摘要、封面的来源，缺失时视为自动生成
+		article.SummaryAuto = article.SummaryAuto || article.Summary == ""
+		article.CoverAuto = article.CoverAuto || article.CoverUrl == ""
+		applyArticleMetadata(&article)
+		if err := config.DB.Model(&entity.Article{}).Where("id = ?", article.ID).UpdateColumns(map[string]interface{}{
+			"word_count":   article.WordCount,
+			"reading_time": article.ReadingTime,
+			"summary":      article.Summary,
+			"summary_auto": article.SummaryAuto,
+			"cover_url":    article.CoverUrl,
+			"cover_auto":   article.CoverAuto,
+		}).Error; err != nil {
+			log.Printf("补充文章 %d 元数据失败: %v", article.ID, err)
+		}
+	}
+	if len(articles) > 0 {
+		ids := make([]uint, 0, len(articles))
+		for _, article := range articles {
+			ids = append(ids, article.ID)
+		}
+		invalidateArticleCache(ids...)
+		log.Printf("已为%d篇文章补充字数、阅读时间等信息", len(articles))
+	}
+}
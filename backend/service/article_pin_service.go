@@ -0,0 +1,126 @@
+package service
+
+import (
+	"backend/config"
+	"backend/dto"
+	"backend/entity"
+	"errors"
+	"log"
+	"math"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrInvalidPinExpiry 置顶截止时间无效
+var ErrInvalidPinExpiry = errors.New("置顶截止时间必须晚于当前时间")
+
+// isPinnedArticle 判断文章在指定时间是否处于置顶状态
+func isPinnedArticle(article entity.Article, now time.Time) bool {
+	return article.PinOrder > 0 && (article.PinnedUntil == nil || article.PinnedUntil.After(now))
+}
+
+// pinnedFirstOrder 置顶文章优先的排序表达式：有效置顶按pin_order升序，其余文章排在后面
+func pinnedFirstOrder(now time.Time) clause.OrderBy {
+	return clause.OrderBy{Expression: clause.Expr{
+		SQL:  "CASE WHEN pin_order > 0 AND (pinned_until IS NULL OR pinned_until > ?) THEN pin_order ELSE ? END",
+		Vars: []interface{}{now, math.MaxInt32},
+	}}
+}
+
+// PinArticle 置顶文章，可指定截止时间（到期后自动恢复正常排序）
+func PinArticle(id uint, req dto.ArticlePinRequest) (*dto.ArticleResponse, error) {
+	if req.PinnedUntil != nil && !req.PinnedUntil.After(time.Now()) {
+		return nil, ErrInvalidPinExpiry
+	}
+	if err := updateArticleColumns(id, map[string]interface{}{
+		"pin_order":    req.PinOrder,
+		"pinned_until": req.PinnedUntil,
+	}); err != nil {
+		return nil, err
+	}
+	return GetArticleByID(id)
+}
+
+// UnpinArticle 取消置顶
+func UnpinArticle(id uint) (*dto.ArticleResponse, error) {
+	if err := updateArticleColumns(id, map[string]interface{}{
+		"pin_order":    0,
+		"pinned_until": nil,
+	}); err != nil {
+		return nil, err
+	}
+	return GetArticleByID(id)
+}
+
+// clearExpiredPins 清除已到期的置顶（由定时发布任务调用），使列表缓存失效，避免缓存期间仍按旧置顶排序
+func clearExpiredPins() {
+	now := time.Now()
+	var ids []uint
+	if err := config.DB.Model(&entity.Article{}).
+		Where("pin_order > 0 AND pinned_until IS NOT NULL AND pinned_until <= ?", now).
+		Pluck("id", &ids).Error; err != nil {
+		log.Printf("查询到期置顶文章失败: %v", err)
+		return
+	}
+	if len(ids) == 0 {
+		return
+	}
+
+	// 条件更新，避免覆盖期间重新设置的置顶；与取消置顶一样不修改更新时间，版本号加1
+	result := config.DB.Model(&entity.Article{}).
+		Where("id IN ? AND pin_order > 0 AND pinned_until IS NOT NULL AND pinned_until <= ?", ids, now).
+		UpdateColumns(map[string]interface{}{
+			"pin_order":    0,
+			"pinned_until": nil,
+			"version":      gorm.Expr("version + 1"),
+		})
+	if result.Error != nil {
+		log.Printf("清除到期置顶失败: %v", result.Error)
+		return
+	}
+	if result.RowsAffected > 0 {
+		invalidateArticleCache(ids...)
+		log.Printf("已清除%d篇文章的到期置顶", result.RowsAffected)
+	}
+}
+
+// SetArticleFeatured 设置或取消精选
+func SetArticleFeatured(id uint, featured bool) (*dto.ArticleResponse, error) {
+	if err := updateArticleColumns(id, map[string]interface{}{"featured": featured}); err != nil {
+		return nil, err
+	}
+	return GetArticleByID(id)
+}
+
+// GetFeaturedArticles 获取精选的已发布文章（首页轮播），置顶的在前，其余按发布时间倒序
+func GetFeaturedArticles(limit int) ([]dto.ArticleListResponse, error) {
+	var articles []entity.Article
+	if err := config.DB.Preload("Tags").
+		Where("featured = ? AND status = ?", true, entity.ArticleStatusPublished).
+		Order(pinnedFirstOrder(time.Now())).Order("published_at desc").Order("id desc").
+		Limit(limit).Find(&articles).Error; err != nil {
+		return nil, err
+	}
+
+	list := make([]dto.ArticleListResponse, 0, len(articles))
+	for _, a := range articles {
+		list = append(list, mapToListResponse(a))
+	}
+	return list, nil
+}
+
+// updateArticleColumns 更新文章的展示属性（不修改更新时间，版本号加1）并清除缓存，文章不存在时返回gorm.ErrRecordNotFound
+func updateArticleColumns(id uint, columns map[string]interface{}) error {
+	var article entity.Article
+	if err := config.DB.Select("id").First(&article, id).Error; err != nil {
+		return err
+	}
+	columns["version"] = gorm.Expr("version + 1")
+	if err := config.DB.Model(&entity.Article{}).Where("id = ?", id).UpdateColumns(columns).Error; err != nil {
+		return err
+	}
+	invalidateArticleCache(id)
+	return nil
+}
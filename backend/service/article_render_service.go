@@ -0,0 +1,47 @@
+package service
+
+import (
+	"backend/config"
+	"backend/entity"
+	"backend/utils"
+	"log"
+)
+
+// renderArticleContent 将文章正文渲染为HTML并生成目录，结果保存在文章实体上
+func renderArticleContent(article *entity.Article) {
+	html, toc, err := utils.RenderMarkdown(article.Content)
+	if err != nil {
+		log.Printf("渲染文章 %d 失败: %v", article.ID, err)
+		article.ContentHTML, article.Toc = "", ""
+		return
+	}
+	article.ContentHTML = html
+	article.Toc = utils.ToJSONString(toc)
+}
+
+// ensureArticleRendered 历史文章没有渲染结果时补充渲染并回写数据库（不修改更新时间）
+func ensureArticleRendered(article *entity.Article) {
+	if article.ContentHTML != "" || article.Content == "" {
+		return
+	}
+	renderArticleContent(article)
+	if article.ContentHTML == "" {
+		return
+	}
+	if err := config.DB.Model(&entity.Article{}).Where("id = ?", article.ID).
+		UpdateColumns(map[string]interface{}{"content_html": article.ContentHTML, "toc": article.Toc}).Error; err != nil {
+		log.Printf("保存文章 %d 渲染结果失败: %v", article.ID, err)
+	}
+}
+
+// parseArticleToc 解析保存的目录JSON
+func parseArticleToc(toc string) []*utils.TocItem {
+	if toc == "" {
+		return nil
+	}
+	var items []*utils.TocItem
+	if err := utils.ParseJSONString(toc, &items); err != nil {
+		return nil
+	}
+	return items
+}
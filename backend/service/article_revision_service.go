@@ -0,0 +1,172 @@
+package service
+
+import (
+	"backend/config"
+	"backend/dto"
+	"backend/entity"
+	"backend/utils"
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// createRevisionTx 在事务中为文章当前状态创建一条修订记录
+func createRevisionTx(tx *gorm.DB, article entity.Article, tags []entity.Tag, editor string) error {
+	var maxVersion int
+	if err := tx.Model(&entity.ArticleRevision{}).Where("article_id = ?", article.ID).
+		Select("COALESCE(MAX(version), 0)").Scan(&maxVersion).Error; err != nil {
+		return err
+	}
+
+	tagIds := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		tagIds = append(tagIds, strconv.FormatUint(uint64(tag.ID), 10))
+	}
+
+	revision := entity.ArticleRevision{
+		ArticleID:   article.ID,
+		Version:     maxVersion + 1,
+		Title:       article.Title,
+		Content:     article.Content,
+		Summary:     article.Summary,
+		CoverUrl:    article.CoverUrl,
+		SummaryAuto: article.SummaryAuto,
+		CoverAuto:   article.CoverAuto,
+		TagIds:      strings.Join(tagIds, ","),
+		Editor:      editor,
+	}
+	return tx.Create(&revision).Error
+}
+
+// ensureBaselineRevisionTx 文章还没有修订记录时（历史文章），先保存修改前的快照
+func ensureBaselineRevisionTx(tx *gorm.DB, article entity.Article) error {
+	var tags []entity.Tag
+	if err := tx.Model(&article).Association("Tags").Find(&tags); err != nil {
+		return err
+	}
+
+	var count int64
+	if err := tx.Model(&entity.ArticleRevision{}).Where("article_id = ?", article.ID).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return nil
+	}
+	return createRevisionTx(tx, article, tags, "")
+}
+
+// GetArticleRevisions 获取文章的修订记录列表（按版本倒序，不含正文）
+func GetArticleRevisions(articleID uint) ([]dto.ArticleRevisionResponse, error) {
+	var revisions []entity.ArticleRevision
+	if err := config.DB.Omit("content").Where("article_id = ?", articleID).
+		Order("version desc").Find(&revisions).Error; err != nil {
+		return nil, err
+	}
+
+	responses := make([]dto.ArticleRevisionResponse, 0, len(revisions))
+	for _, r := range revisions {
+		responses = append(responses, mapRevisionToResponse(r))
+	}
+	return responses, nil
+}
+
+// GetArticleRevision 获取文章指定版本的修订记录
+func GetArticleRevision(articleID uint, version int) (*dto.ArticleRevisionResponse, error) {
+	revision, err := findRevision(articleID, version)
+	if err != nil {
+		return nil, err
+	}
+	resp := mapRevisionToResponse(*revision)
+	return &resp, nil
+}
+
+// DiffArticleRevisions 比较文章两个修订版本的差异
+func DiffArticleRevisions(articleID uint, fromVersion, toVersion int) (*dto.ArticleRevisionDiffResponse, error) {
+	from, err := findRevision(articleID, fromVersion)
+	if err != nil {
+		return nil, err
+	}
+	to, err := findRevision(articleID, toVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	lines := utils.DiffLines(from.Content, to.Content)
+	resp := &dto.ArticleRevisionDiffResponse{
+		From:     fromVersion,
+		To:       toVersion,
+		Title:    dto.FieldChange{From: from.Title, To: to.Title, Changed: from.Title != to.Title},
+		Summary:  dto.FieldChange{From: from.Summary, To: to.Summary, Changed: from.Summary != to.Summary},
+		CoverUrl: dto.FieldChange{From: from.CoverUrl, To: to.CoverUrl, Changed: from.CoverUrl != to.CoverUrl},
+		TagIds: dto.FieldChange{
+			From:    parseTagIds(from.TagIds),
+			To:      parseTagIds(to.TagIds),
+			Changed: from.TagIds != to.TagIds,
+		},
+		Content: lines,
+	}
+	for _, line := range lines {
+		switch line.Type {
+		case utils.DiffInsert:
+			resp.Added++
+		case utils.DiffDelete:
+			resp.Removed++
+		}
+	}
+	return resp, nil
+}
+
+// RestoreArticleRevision 将文章恢复到指定版本（作为一次新的更新，会产生新的修订记录），ifMatch为0时不校验文章版本
+func RestoreArticleRevision(articleID uint, version int, editor string, ifMatch uint) (*dto.ArticleResponse, error) {
+	revision, err := findRevision(articleID, version)
+	if err != nil {
+		return nil, err
+	}
+
+	// 修订记录不包含slug和状态，只恢复内容相关字段；自动生成的摘要与封面（null）恢复后仍自动生成
+	return PatchArticle(articleID, dto.ArticlePatchRequest{
+		Title:    dto.PatchField[string]{Set: true, Value: revision.Title},
+		Content:  dto.PatchField[string]{Set: true, Value: revision.Content},
+		Summary:  dto.PatchField[string]{Set: true, Null: revision.SummaryAuto, Value: revision.Summary},
+		CoverUrl: dto.PatchField[string]{Set: true, Null: revision.CoverAuto, Value: revision.CoverUrl},
+		TagIds:   dto.PatchField[[]uint]{Set: true, Value: parseTagIds(revision.TagIds)},
+	}, editor, ifMatch)
+}
+
+// findRevision 查询文章指定版本
+func findRevision(articleID uint, version int) (*entity.ArticleRevision, error) {
+	var revision entity.ArticleRevision
+	if err := config.DB.Where("article_id = ? AND version = ?", articleID, version).
+		First(&revision).Error; err != nil {
+		return nil, err
+	}
+	return &revision, nil
+}
+
+// mapRevisionToResponse 将修订记录转换为响应 DTO
+func mapRevisionToResponse(r entity.ArticleRevision) dto.ArticleRevisionResponse {
+	return dto.ArticleRevisionResponse{
+		ID:        r.ID,
+		ArticleId: r.ArticleID,
+		Version:   r.Version,
+		Title:     r.Title,
+		Content:   r.Content,
+		Summary:   r.Summary,
+		CoverUrl:  r.CoverUrl,
+		TagIds:    parseTagIds(r.TagIds),
+		Editor:    r.Editor,
+		CreatedAt: r.CreatedAt.Format("2006-01-02 15:04:05"),
+	}
+}
+
+// parseTagIds 解析逗号分隔的标签ID
+func parseTagIds(s string) []uint {
+	tagIds := []uint{}
+	for _, part := range strings.Split(s, ",") {
+		if id, err := strconv.ParseUint(strings.TrimSpace(part), 10, 32); err == nil {
+			tagIds = append(tagIds, uint(id))
+		}
+	}
+	return tagIds
+}
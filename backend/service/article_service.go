@@ -4,17 +4,33 @@ import (
 	"backend/config"
 	"backend/dto"
 	"backend/entity"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
-// CreateArticle 创建文章
-func CreateArticle(req dto.ArticleCreateRequest) (*dto.ArticleResponse, error) {
+// CreateArticle 创建文章，editor为编辑者标识（记录在修订历史中）
+func CreateArticle(req dto.ArticleCreateRequest, editor string) (*dto.ArticleResponse, error) {
 	// 1. 创建文章实体
 	article := entity.Article{
-		Title:    req.Title,
-		Content:  req.Content,
-		Summary:  req.Summary,
-		CoverUrl: req.CoverUrl,
+		Title:       req.Title,
+		Content:     req.Content,
+		Summary:     req.Summary,
+		CoverUrl:    req.CoverUrl,
+		SummaryAuto: req.Summary == "",
+		CoverAuto:   req.CoverUrl == "",
+	}
+	status := req.Status
+	if status == "" {
+		status = entity.ArticleStatusPublished
+	}
+	if err := applyArticleStatus(&article, status, req.PublishedAt); err != nil {
+		return nil, err
 	}
+	applyArticleMetadata(&article)
+	renderArticleContent(&article)
 
 	// 2. 开始数据库事务
 	tx := config.DB.Begin()
@@ -24,15 +40,19 @@ func CreateArticle(req dto.ArticleCreateRequest) (*dto.ArticleResponse, error) {
 		}
 	}()
 
-	// 3. 创建文章
+	// 3. 生成slug并创建文章
+	if err := assignArticleSlugTx(tx, &article, req.Slug); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
 	if err := tx.Create(&article).Error; err != nil {
 		tx.Rollback()
 		return nil, err
 	}
 
 	// 4. 处理标签关联
+	var tags []entity.Tag
 	if len(req.TagIds) > 0 {
-		var tags []entity.Tag
 		if err := tx.Where("id IN ?", req.TagIds).Find(&tags).Error; err != nil {
 			tx.Rollback()
 			return nil, err
@@ -44,44 +64,146 @@ func CreateArticle(req dto.ArticleCreateRequest) (*dto.ArticleResponse, error) {
 		}
 	}
 
-	// 5. 提交事务
+	// 5. 记录初始修订版本
+	if err := createRevisionTx(tx, article, tags, editor); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	// 6. 提交事务
 	if err := tx.Commit().Error; err != nil {
 		return nil, err
 	}
+	syncSearchIndex(article)
+	onArticlesChanged()
 
-	// 6. 重新查询完整数据并返回
+	// 7. 重新查询完整数据并返回
 	return GetArticleByID(article.ID)
 }
 
-// GetArticleList 获取文章列表，支持分页
-func GetArticleList(page, pageSize int) ([]dto.ArticleListResponse, error) {
-	var articles []entity.Article
-	offset := (page - 1) * pageSize
+// articleSortColumns 文章列表允许的排序字段
+var articleSortColumns = map[string]string{
+	"createdAt":   "created_at",
+	"updatedAt":   "updated_at",
+	"publishedAt": "published_at",
+	"views":       "views",
+	"likes":       "likes",
+}
+
+// IsValidArticleSort 判断文章列表排序字段是否合法
+func IsValidArticleSort(sortBy string) bool {
+	_, ok := articleSortColumns[sortBy]
+	return ok
+}
+
+// GetArticleList 获取文章列表，支持按标签、日期、关键词筛选，排序与分页（结果缓存在Redis中，文章变化时失效）
+func GetArticleList(q dto.ArticleListQuery) (*dto.PageResponse, error) {
+	list := make([]dto.ArticleListResponse, 0)
+	page := &dto.PageResponse{List: &list}
+	if err := loadWithCache(articleListCacheKey(q), articleListCacheTTL, page, func() (interface{}, error) {
+		return queryArticleList(q)
+	}); err != nil {
+		return nil, err
+	}
+	page.List = list
+	return page, nil
+}
+
+// queryArticleList 从数据库查询文章列表
+func queryArticleList(q dto.ArticleListQuery) (*dto.PageResponse, error) {
+	query := config.DB.Model(&entity.Article{})
+
+	// 状态筛选：公开接口只返回已发布的文章
+	if q.Public {
+		query = query.Where("status = ?", entity.ArticleStatusPublished)
+	} else if q.Status != "" {
+		query = query.Where("status = ?", q.Status)
+	}
+	// 标签筛选
+	if len(q.TagIds) > 0 {
+		tagQuery := config.DB.Table("article_tags").Select("article_id").Where("tag_id IN ?", q.TagIds)
+		if q.TagMode == "all" {
+			tagQuery = tagQuery.Group("article_id").Having("COUNT(DISTINCT tag_id) = ?", len(uniqueUints(q.TagIds)))
+		}
+		query = query.Where("id IN (?)", tagQuery)
+	}
+	// 日期范围
+	if q.StartDate != nil {
+		query = query.Where("created_at >= ?", *q.StartDate)
+	}
+	if q.EndDate != nil {
+		query = query.Where("created_at < ?", *q.EndDate)
+	}
+	// 归档范围
+	if q.ArchiveFrom != nil {
+		query = query.Where(archiveDateExpr+" >= ?", *q.ArchiveFrom)
+	}
+	if q.ArchiveTo != nil {
+		query = query.Where(archiveDateExpr+" < ?", *q.ArchiveTo)
+	}
+	// 关键词
+	if q.Keyword != "" {
+		like := "%" + escapeLike(q.Keyword) + "%"
+		query = query.Where("title LIKE ? OR summary LIKE ?", like, like)
+	}
+
+	query = query.Session(&gorm.Session{})
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, err
+	}
+
+	column, ok := articleSortColumns[q.SortBy]
+	if !ok {
+		column = "created_at"
+	}
+	direction := "desc"
+	if q.Order == "asc" {
+		direction = "asc"
+	}
 
+	var articles []entity.Article
+	offset := (q.Page - 1) * q.PageSize
+	listQuery := query.Preload("Tags")
+	if q.PinnedFirst {
+		// 置顶排序只影响顺序，不影响总数与分页
+		listQuery = listQuery.Order(pinnedFirstOrder(time.Now()))
+	}
 	// 预加载标签关系
-	if err := config.DB.Preload("Tags").Order("created_at desc").Offset(offset).Limit(pageSize).Find(&articles).Error; err != nil {
+	if err := listQuery.Order(column + " " + direction).Order("id " + direction).
+		Offset(offset).Limit(q.PageSize).Find(&articles).Error; err != nil {
 		return nil, err
 	}
 
-	var list []dto.ArticleListResponse
+	list := make([]dto.ArticleListResponse, 0, len(articles))
 	for _, a := range articles {
-		// 提取标签ID
-		var tagIds []uint
-		for _, tag := range a.Tags {
-			tagIds = append(tagIds, tag.ID)
+		list = append(list, mapToListResponse(a))
+	}
+	return &dto.PageResponse{
+		List:     list,
+		Total:    total,
+		Page:     q.Page,
+		PageSize: q.PageSize,
+	}, nil
+}
+
+// escapeLike 转义LIKE语句中的通配符
+func escapeLike(s string) string {
+	return strings.NewReplacer("\\", "\\\\", "%", "\\%", "_", "\\_").Replace(s)
+}
+
+// uniqueUints 去重
+func uniqueUints(items []uint) []uint {
+	seen := make(map[uint]struct{}, len(items))
+	result := make([]uint, 0, len(items))
+	for _, item := range items {
+		if _, ok := seen[item]; ok {
+			continue
 		}
-		list = append(list, dto.ArticleListResponse{
-			ID:        a.ID,
-			Title:     a.Title,
-			Summary:   a.Summary,
-			CoverUrl:  a.CoverUrl,
-			Views:     a.Views, // 添加阅读量字段
-			TagIds:    tagIds,
-			CreatedAt: a.CreatedAt.Format("2006-01-02 15:04:05"),
-			UpdatedAt: a.UpdatedAt.Format("2006-01-02 15:04:05"),
-		})
+		seen[item] = struct{}{}
+		result = append(result, item)
 	}
-	return list, nil
+	return result
 }
 
 // GetArticleByID 根据 ID 获取文章详情
@@ -90,11 +212,93 @@ func GetArticleByID(id uint) (*dto.ArticleResponse, error) {
 	if err := config.DB.Preload("Tags").First(&article, id).Error; err != nil {
 		return nil, err
 	}
-	return mapToResponse(article), nil
+	ensureArticleRendered(&article)
+
+	resp := mapToResponse(article)
+	series, err := getArticleSeriesInfo(article)
+	if err != nil {
+		return nil, err
+	}
+	resp.Series = series
+	return resp, nil
 }
 
-// UpdateArticle 更新文章
-func UpdateArticle(id uint, req dto.ArticleUpdateRequest) (*dto.ArticleResponse, error) {
+// UpdateArticle 更新文章（完整替换），每次更新都会保存一条修订记录；ifMatch为期望的版本号，0表示不校验
+// 未提供的slug、摘要、封面恢复自动生成，未提供的标签被清空；状态与发布时间未提供时保持不变
+func UpdateArticle(id uint, req dto.ArticleUpdateRequest, editor string, ifMatch uint) (*dto.ArticleResponse, error) {
+	return saveArticleChanges(id, ifMatch, editor, &req.TagIds, func(tx *gorm.DB, article *entity.Article) error {
+		article.Title = req.Title
+		article.Content = req.Content
+		article.Summary = req.Summary
+		article.SummaryAuto = req.Summary == ""
+		article.CoverUrl = req.CoverUrl
+		article.CoverAuto = req.CoverUrl == ""
+		if req.Slug == "" {
+			article.SlugAuto = true
+		}
+		if err := assignArticleSlugTx(tx, article, req.Slug); err != nil {
+			return err
+		}
+		if req.Status != "" || req.PublishedAt != nil {
+			return applyArticleStatus(article, req.Status, req.PublishedAt)
+		}
+		return nil
+	})
+}
+
+// PatchArticle 按JSON Merge Patch修改文章，只修改请求中出现的字段，每次修改都会保存一条修订记录
+func PatchArticle(id uint, req dto.ArticlePatchRequest, editor string, ifMatch uint) (*dto.ArticleResponse, error) {
+	if err := checkPatchRequired(req.Title, "title"); err != nil {
+		return nil, err
+	}
+	if err := checkPatchRequired(req.Content, "content"); err != nil {
+		return nil, err
+	}
+	if err := checkPatchRequired(req.Status, "status"); err != nil {
+		return nil, err
+	}
+
+	var tagIds *[]uint
+	if req.TagIds.Set {
+		tagIds = &req.TagIds.Value
+	}
+	return saveArticleChanges(id, ifMatch, editor, tagIds, func(tx *gorm.DB, article *entity.Article) error {
+		if req.Title.HasValue() {
+			article.Title = req.Title.Value
+		}
+		if req.Content.HasValue() {
+			article.Content = req.Content.Value
+		}
+		// null恢复自动生成，空字符串表示作者清空
+		if req.Summary.Set {
+			article.Summary = req.Summary.Value
+			article.SummaryAuto = req.Summary.Null
+		}
+		if req.CoverUrl.Set {
+			article.CoverUrl = req.CoverUrl.Value
+			article.CoverAuto = req.CoverUrl.Null
+		}
+		if req.Slug.Set && req.Slug.Value == "" {
+			article.SlugAuto = true
+		}
+		if err := assignArticleSlugTx(tx, article, req.Slug.Value); err != nil {
+			return err
+		}
+		if req.Status.Set || req.PublishedAt.Set {
+			var publishedAt *time.Time
+			if req.PublishedAt.Null {
+				article.PublishedAt = nil
+			} else if req.PublishedAt.Set {
+				publishedAt = &req.PublishedAt.Value
+			}
+			return applyArticleStatus(article, req.Status.Value, publishedAt)
+		}
+		return nil
+	})
+}
+
+// saveArticleChanges 在事务中修改并保存文章，同时记录修订历史并递增版本号；tagIds为nil表示标签保持不变
+func saveArticleChanges(id, ifMatch uint, editor string, tagIds *[]uint, apply func(tx *gorm.DB, article *entity.Article) error) (*dto.ArticleResponse, error) {
 	// 开始事务
 	tx := config.DB.Begin()
 	defer func() {
@@ -103,36 +307,55 @@ func UpdateArticle(id uint, req dto.ArticleUpdateRequest) (*dto.ArticleResponse,
 		}
 	}()
 
+	// 锁定文章，避免并发编辑在版本校验之后互相覆盖
 	var article entity.Article
-	if err := tx.First(&article, id).Error; err != nil {
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&article, id).Error; err != nil {
 		tx.Rollback()
 		return nil, err
 	}
-
-	// 更新基本字段
-	if req.Title != "" {
-		article.Title = req.Title
-	}
-	if req.Content != "" {
-		article.Content = req.Content
+	if err := checkVersion(ifMatch, article.Version); err != nil {
+		tx.Rollback()
+		return nil, err
 	}
-	if req.Summary != "" {
-		article.Summary = req.Summary
+
+	// 历史文章没有修订记录时，先保存修改前的版本
+	if err := ensureBaselineRevisionTx(tx, article); err != nil {
+		tx.Rollback()
+		return nil, err
 	}
-	if req.CoverUrl != "" {
-		article.CoverUrl = req.CoverUrl
+
+	// 更新基本字段
+	oldSlug := article.Slug
+	if err := apply(tx, &article); err != nil {
+		tx.Rollback()
+		return nil, err
 	}
 
+	applyArticleMetadata(&article)
+	renderArticleContent(&article)
+	article.Version++
+
 	// 保存基本信息
 	if err := tx.Save(&article).Error; err != nil {
 		tx.Rollback()
 		return nil, err
 	}
+	if err := recordSlugRedirectTx(tx, article.ID, oldSlug, article.Slug); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
 
 	// 更新标签关联
-	if len(req.TagIds) > 0 {
-		var tags []entity.Tag
-		if err := tx.Where("id IN ?", req.TagIds).Find(&tags).Error; err != nil {
+	var tags []entity.Tag
+	switch {
+	case tagIds == nil:
+		// 标签保持不变，修订记录沿用当前标签
+		if err := tx.Model(&article).Association("Tags").Find(&tags); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	case len(*tagIds) > 0:
+		if err := tx.Where("id IN ?", *tagIds).Find(&tags).Error; err != nil {
 			tx.Rollback()
 			return nil, err
 		}
@@ -141,7 +364,7 @@ func UpdateArticle(id uint, req dto.ArticleUpdateRequest) (*dto.ArticleResponse,
 			tx.Rollback()
 			return nil, err
 		}
-	} else {
+	default:
 		// 如果标签ID列表为空，清除所有标签关联
 		if err := tx.Model(&article).Association("Tags").Clear(); err != nil {
 			tx.Rollback()
@@ -149,17 +372,25 @@ func UpdateArticle(id uint, req dto.ArticleUpdateRequest) (*dto.ArticleResponse,
 		}
 	}
 
+	// 保存修订记录
+	if err := createRevisionTx(tx, article, tags, editor); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
 	// 提交事务
 	if err := tx.Commit().Error; err != nil {
 		return nil, err
 	}
+	syncSearchIndex(article)
+	onArticlesChanged(article.ID)
 
 	// 重新查询完整数据并返回
 	return GetArticleByID(article.ID)
 }
 
-// DeleteArticle 删除文章
-func DeleteArticle(id uint) error {
+// DeleteArticle 删除文章（移入回收站），ifMatch为期望的版本号，0表示不校验
+func DeleteArticle(id, ifMatch uint) error {
 	tx := config.DB.Begin()
 	defer func() {
 		if r := recover(); r != nil {
@@ -168,24 +399,39 @@ func DeleteArticle(id uint) error {
 	}()
 
 	var article entity.Article
-	if err := tx.First(&article, id).Error; err != nil {
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&article, id).Error; err != nil {
 		tx.Rollback()
 		return err
 	}
-
-	// 先清除标签关联
-	if err := tx.Model(&article).Association("Tags").Clear(); err != nil {
+	if err := checkVersion(ifMatch, article.Version); err != nil {
 		tx.Rollback()
 		return err
 	}
 
-	// 删除文章
-	if err := tx.Delete(&article).Error; err != nil {
+	// 软删除文章及其评论（使用同一删除时间，从回收站恢复时一并恢复），标签关联保留
+	deletedAt := trashDeleteTime()
+	if err := softDeleteTx(tx, deletedAt, &entity.Comment{}, "article_id = ?", id); err != nil {
 		tx.Rollback()
 		return err
 	}
+	if err := softDeleteTx(tx, deletedAt, &entity.Article{}, "id = ?", id); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return err
+	}
+	searchIndex.remove(id)
+	onArticlesChanged(id)
+	return nil
+}
 
-	return tx.Commit().Error
+// onArticlesChanged 文章内容或状态变化后清除文章与订阅源缓存，并通知后台重新计算相关文章
+func onArticlesChanged(articleIDs ...uint) {
+	invalidateArticleCache(withSeriesSiblings(articleIDs)...)
+	invalidateFeedCache()
+	scheduleRelatedRebuild()
 }
 
 // mapToResponse 将实体转换为响应 DTO
@@ -201,14 +447,68 @@ func mapToResponse(article entity.Article) *dto.ArticleResponse {
 	}
 
 	return &dto.ArticleResponse{
-		ID:        article.ID,
-		Title:     article.Title,
-		Content:   article.Content,
-		Summary:   article.Summary,
-		CoverUrl:  article.CoverUrl,
-		Views:     article.Views, // 添加阅读量字段
-		TagIds:    tagIds,
-		CreatedAt: article.CreatedAt.Format("2006-01-02 15:04:05"),
-		UpdatedAt: article.UpdatedAt.Format("2006-01-02 15:04:05"),
+		ID:          article.ID,
+		Title:       article.Title,
+		Slug:        article.Slug,
+		Content:     article.Content,
+		Summary:     article.Summary,
+		CoverUrl:    article.CoverUrl,
+		Views:       article.Views, // 添加阅读量字段
+		Likes:       article.Likes,
+		WordCount:   article.WordCount,
+		ReadingTime: article.ReadingTime,
+		TagIds:      tagIds,
+		Status:      article.Status,
+		PublishedAt: formatOptionalTime(article.PublishedAt),
+		Pinned:      isPinnedArticle(article, time.Now()),
+		PinOrder:    article.PinOrder,
+		PinnedUntil: formatOptionalTime(article.PinnedUntil),
+		Featured:    article.Featured,
+		Version:     article.Version,
+		CreatedAt:   article.CreatedAt.Format("2006-01-02 15:04:05"),
+		UpdatedAt:   article.UpdatedAt.Format("2006-01-02 15:04:05"),
+		Reactions:   completeReactionCounts(parseReactionCounts(article.Reactions)),
+		Html:        article.ContentHTML,
+		Toc:         parseArticleToc(article.Toc),
+	}
+}
+
+// mapToListResponse 将实体转换为列表响应 DTO
+func mapToListResponse(article entity.Article) dto.ArticleListResponse {
+	// 提取标签ID
+	var tagIds []uint
+	for _, tag := range article.Tags {
+		tagIds = append(tagIds, tag.ID)
+	}
+
+	return dto.ArticleListResponse{
+		ID:          article.ID,
+		Title:       article.Title,
+		Slug:        article.Slug,
+		Summary:     article.Summary,
+		CoverUrl:    article.CoverUrl,
+		Views:       article.Views, // 添加阅读量字段
+		Likes:       article.Likes,
+		WordCount:   article.WordCount,
+		ReadingTime: article.ReadingTime,
+		TagIds:      tagIds,
+		Status:      article.Status,
+		PublishedAt: formatOptionalTime(article.PublishedAt),
+		Pinned:      isPinnedArticle(article, time.Now()),
+		PinOrder:    article.PinOrder,
+		PinnedUntil: formatOptionalTime(article.PinnedUntil),
+		Featured:    article.Featured,
+		Version:     article.Version,
+		CreatedAt:   article.CreatedAt.Format("2006-01-02 15:04:05"),
+		UpdatedAt:   article.UpdatedAt.Format("2006-01-02 15:04:05"),
+		Reactions:   completeReactionCounts(parseReactionCounts(article.Reactions)),
+	}
+}
+
+// formatOptionalTime 格式化可能为空的时间
+func formatOptionalTime(t *time.Time) string {
+	if t == nil {
+		return ""
 	}
+	return t.Format("2006-01-02 15:04:05")
 }
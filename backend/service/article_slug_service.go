@@ -0,0 +1,175 @@
+package service
+
+import (
+	"backend/config"
+	"backend/entity"
+	"backend/utils"
+	"errors"
+	"log"
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrInvalidSlug slug格式不合法
+	ErrInvalidSlug = errors.New("slug只能包含小写字母、数字和连字符，且不超过80个字符")
+	// ErrSlugTaken slug已被其他文章使用
+	ErrSlugTaken = errors.New("slug已被其他文章使用")
+)
+
+// defaultArticleSlug 标题无法生成slug时（如全是符号）使用的默认值
+const defaultArticleSlug = "article"
+
+// assignArticleSlugTx 设置文章slug：作者指定时校验后使用；否则slug为自动生成时随标题重新生成
+func assignArticleSlugTx(tx *gorm.DB, article *entity.Article, manual string) error {
+	if manual != "" {
+		if !utils.IsValidSlug(manual) {
+			return ErrInvalidSlug
+		}
+		var count int64
+		if err := tx.Unscoped().Model(&entity.Article{}).
+			Where("slug = ? AND id <> ?", manual, article.ID).Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			return ErrSlugTaken
+		}
+		// 其他文章的旧slug仍在重定向到该文章，不能占用
+		if err := tx.Unscoped().Model(&entity.ArticleSlugRedirect{}).
+			Where("slug = ? AND article_id <> ?", manual, article.ID).Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			return ErrSlugTaken
+		}
+		article.Slug = manual
+		article.SlugAuto = false
+		return nil
+	}
+
+	if !article.SlugAuto && article.Slug != "" {
+		return nil
+	}
+	base := utils.Slugify(article.Title)
+	if base == "" {
+		base = defaultArticleSlug
+	}
+	// 标题变化不影响slug时保持原值
+	if slugHasBase(article.Slug, base) {
+		article.SlugAuto = true
+		return nil
+	}
+	slug, err := uniqueSlugTx(tx, base, article.ID)
+	if err != nil {
+		return err
+	}
+	article.Slug = slug
+	article.SlugAuto = true
+	return nil
+}
+
+// slugHasBase 判断slug是否为base或base-N的形式
+func slugHasBase(slug, base string) bool {
+	if slug == base {
+		return true
+	}
+	suffix := strings.TrimPrefix(slug, base+"-")
+	if suffix == slug {
+		return false
+	}
+	_, err := strconv.Atoi(suffix)
+	return err == nil
+}
+
+// uniqueSlugTx 生成未被其他文章（包括其旧slug）占用的slug，冲突时追加序号
+func uniqueSlugTx(tx *gorm.DB, base string, articleID uint) (string, error) {
+	for i := 1; ; i++ {
+		slug := base
+		if i > 1 {
+			slug = base + "-" + strconv.Itoa(i)
+		}
+
+		var count int64
+		if err := tx.Unscoped().Model(&entity.Article{}).
+			Where("slug = ? AND id <> ?", slug, articleID).Count(&count).Error; err != nil {
+			return "", err
+		}
+		if count > 0 {
+			continue
+		}
+		if err := tx.Model(&entity.ArticleSlugRedirect{}).
+			Where("slug = ? AND article_id <> ?", slug, articleID).Count(&count).Error; err != nil {
+			return "", err
+		}
+		if count == 0 {
+			return slug, nil
+		}
+	}
+}
+
+// recordSlugRedirectTx slug变化后保留旧slug作为重定向，新slug若曾是本文章的旧slug则移除对应的重定向
+func recordSlugRedirectTx(tx *gorm.DB, articleID uint, oldSlug, newSlug string) error {
+	if oldSlug == newSlug {
+		return nil
+	}
+	if err := tx.Unscoped().Where("slug = ? AND article_id = ?", newSlug, articleID).
+		Delete(&entity.ArticleSlugRedirect{}).Error; err != nil {
+		return err
+	}
+	if oldSlug == "" {
+		return nil
+	}
+	return tx.Create(&entity.ArticleSlugRedirect{Slug: oldSlug, ArticleID: articleID}).Error
+}
+
+// ResolveArticleSlug 根据slug查找文章ID；命中旧slug时返回文章当前的slug，redirected为true
+func ResolveArticleSlug(slug string) (id uint, currentSlug string, redirected bool, err error) {
+	var article entity.Article
+	err = config.DB.Select("id", "slug").Where("slug = ?", slug).First(&article).Error
+	if err == nil {
+		return article.ID, article.Slug, false, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return 0, "", false, err
+	}
+
+	var redirect entity.ArticleSlugRedirect
+	if err = config.DB.Where("slug = ?", slug).First(&redirect).Error; err != nil {
+		return 0, "", false, err
+	}
+	if err = config.DB.Select("id", "slug").First(&article, redirect.ArticleID).Error; err != nil {
+		return 0, "", false, err
+	}
+	return article.ID, article.Slug, true, nil
+}
+
+// BackfillArticleSlugs 为没有slug的历史文章按标题生成slug
+func BackfillArticleSlugs() {
+	var articles []entity.Article
+	if err := config.DB.Select("id", "title", "slug", "slug_auto").
+		Where("slug IS NULL OR slug = ''").Order("id").Find(&articles).Error; err != nil {
+		log.Printf("查询待生成slug的文章失败: %v", err)
+		return
+	}
+
+	for _, article := range articles {
+		if err := assignArticleSlugTx(config.DB, &article, ""); err != nil {
+			log.Printf("生成文章 %d 的slug失败: %v", article.ID, err)
+			continue
+		}
+		if err := config.DB.Model(&entity.Article{}).Where("id = ?", article.ID).
+			UpdateColumns(map[string]interface{}{"slug": article.Slug, "slug_auto": true}).Error; err != nil {
+			log.Printf("保存文章 %d 的slug失败: %v", article.ID, err)
+		}
+	}
+	if len(articles) > 0 {
+		ids := make([]uint, 0, len(articles))
+		for _, article := range articles {
+			ids = append(ids, article.ID)
+		}
+		invalidateArticleCache(ids...)
+		log.Printf("已为%d篇文章生成slug", len(articles))
+	}
+}
@@ -0,0 +1,112 @@
+package service
+
+import (
+	"backend/config"
+	"backend/entity"
+	"errors"
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrInvalidArticleStatus 无效的文章状态
+	ErrInvalidArticleStatus = errors.New("无效的文章状态，可选值：draft/published/scheduled/archived")
+	// ErrInvalidPublishTime 定时发布时间无效
+	ErrInvalidPublishTime = errors.New("定时发布需要指定晚于当前时间的publishedAt")
+	// ErrArticleNotPublic 文章未发布，对公众不可见
+	ErrArticleNotPublic = errors.New("文章未发布")
+)
+
+// isValidArticleStatus 判断文章状态是否合法
+func isValidArticleStatus(status string) bool {
+	switch status {
+	case entity.ArticleStatusDraft, entity.ArticleStatusPublished,
+		entity.ArticleStatusScheduled, entity.ArticleStatusArchived:
+		return true
+	}
+	return false
+}
+
+// applyArticleStatus 校验并设置文章状态与发布时间
+func applyArticleStatus(article *entity.Article, status string, publishedAt *time.Time) error {
+	if status == "" {
+		status = article.Status
+	}
+	if !isValidArticleStatus(status) {
+		return ErrInvalidArticleStatus
+	}
+	if publishedAt != nil {
+		t := *publishedAt
+		article.PublishedAt = &t
+	}
+
+	now := time.Now()
+	switch status {
+	case entity.ArticleStatusScheduled:
+		if article.PublishedAt == nil || !article.PublishedAt.After(now) {
+			return ErrInvalidPublishTime
+		}
+	case entity.ArticleStatusPublished:
+		// 首次发布时记录发布时间
+		if article.PublishedAt == nil || (article.Status != entity.ArticleStatusPublished && publishedAt == nil) {
+			article.PublishedAt = &now
+		}
+	}
+	article.Status = status
+	return nil
+}
+
+// isPublicArticle 判断文章是否对公众可见
+func isPublicArticle(article entity.Article) bool {
+	return article.Status == entity.ArticleStatusPublished
+}
+
+// StartArticlePublishTask 启动定时发布任务，到期的定时文章自动发布，到期的置顶自动取消
+func StartArticlePublishTask() {
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+
+	log.Println("启动文章定时发布任务，每1分钟执行一次")
+
+	publishDueArticles()
+	clearExpiredPins()
+	for range ticker.C {
+		publishDueArticles()
+		clearExpiredPins()
+	}
+}
+
+// publishDueArticles 发布所有已到发布时间的定时文章
+func publishDueArticles() {
+	var articles []entity.Article
+	if err := config.DB.Where("status = ? AND published_at <= ?", entity.ArticleStatusScheduled, time.Now()).
+		Find(&articles).Error; err != nil {
+		log.Printf("查询待发布文章失败: %v", err)
+		return
+	}
+
+	var published []uint
+	for _, article := range articles {
+		// 条件更新，避免与手动修改状态冲突；状态属于文章内容，版本号加1使编辑中的旧版本失效
+		result := config.DB.Model(&entity.Article{}).
+			Where("id = ? AND status = ?", article.ID, entity.ArticleStatusScheduled).
+			Updates(map[string]interface{}{"status": entity.ArticleStatusPublished, "version": gorm.Expr("version + 1")})
+		if result.Error != nil {
+			log.Printf("发布文章 %d 失败: %v", article.ID, result.Error)
+			continue
+		}
+		if result.RowsAffected == 0 {
+			continue
+		}
+
+		article.Status = entity.ArticleStatusPublished
+		syncSearchIndex(article)
+		published = append(published, article.ID)
+		log.Printf("定时文章 %d 已发布", article.ID)
+	}
+	if len(published) > 0 {
+		onArticlesChanged(published...)
+	}
+}
@@ -0,0 +1,442 @@
+package service
+
+import (
+	"archive/zip"
+	"backend/config"
+	"backend/dto"
+	"backend/entity"
+	"backend/utils"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// importMaxEntrySize 压缩包中单个文件解压后的最大大小
+const importMaxEntrySize = 20 << 20
+
+// imageDir 本地图片目录（与图片上传接口一致）
+const imageDir = "images"
+
+// localImageRe 匹配引用本地图片的地址：images/、/images/、./images/、api/images/、/api/images/ 开头
+var localImageRe = regexp.MustCompile(`(^|[\s("'=])((?:\.?/)?(?:api/)?images/)([^\s)"'?#/\\]+)`)
+
+// ErrImportFileTooLarge 压缩包中的文件过大
+var ErrImportFileTooLarge = errors.New("文件过大")
+
+// ArticleImportMaxSize 导入压缩包的最大大小（字节）
+func ArticleImportMaxSize() int64 {
+	return int64(getEnvInt("ARTICLE_IMPORT_MAX_MB", 50)) << 20
+}
+
+// replaceLocalImages 替换文本中引用的本地图片地址，replace返回新地址，返回false时保留原地址
+func replaceLocalImages(text string, replace func(name string) (string, bool)) string {
+	return localImageRe.ReplaceAllStringFunc(text, func(match string) string {
+		groups := localImageRe.FindStringSubmatch(match)
+		name, err := url.PathUnescape(groups[3])
+		if err != nil || !isSafeImageName(name) {
+			return match
+		}
+		if newURL, ok := replace(name); ok {
+			return groups[1] + newURL
+		}
+		return match
+	})
+}
+
+// isSafeImageName 判断图片文件名是否只是单个文件名（解码后不含路径分隔符和..），防止读写图片目录之外的文件
+func isSafeImageName(name string) bool {
+	return name != "" && name == path.Base(name) && !strings.Contains(name, "..") &&
+		!strings.ContainsAny(name, `/\`)
+}
+
+// localImagePath 图片在本地的路径，路径不在图片目录内时返回false
+func localImagePath(name string) (string, bool) {
+	if !isSafeImageName(name) {
+		return "", false
+	}
+	localPath := filepath.Join(imageDir, name)
+	rel, err := filepath.Rel(imageDir, localPath)
+	if err != nil || rel != filepath.Base(localPath) {
+		return "", false
+	}
+	return localPath, true
+}
+
+// ExportArticles 将文章导出为zip：每篇文章一个带YAML元数据的Markdown文件，引用的本地图片放在images/目录，
+// ids为空时导出全部文章，返回导出的文章数
+func ExportArticles(w io.Writer, ids []uint) (int, error) {
+	query := config.DB.Preload("Tags").Order("id")
+	if len(ids) > 0 {
+		query = query.Where("id IN ?", ids)
+	}
+	var articles []entity.Article
+	if err := query.Find(&articles).Error; err != nil {
+		return 0, err
+	}
+
+	zw := zip.NewWriter(w)
+	images := make(map[string]struct{})
+	for _, article := range articles {
+		content, err := exportArticleMarkdown(article, images)
+		if err != nil {
+			return 0, err
+		}
+		name := article.Slug
+		if name == "" {
+			name = fmt.Sprintf("article-%d", article.ID)
+		}
+		f, err := zw.Create(name + ".md")
+		if err != nil {
+			return 0, err
+		}
+		if _, err := io.WriteString(f, content); err != nil {
+			return 0, err
+		}
+	}
+
+	names := make([]string, 0, len(images))
+	for name := range images {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		localPath, ok := localImagePath(name)
+		if !ok {
+			continue
+		}
+		if err := addFileToZip(zw, path.Join(imageDir, name), localPath); err != nil {
+			return 0, err
+		}
+	}
+	return len(articles), zw.Close()
+}
+
+// exportArticleMarkdown 生成文章的Markdown文件内容，正文与封面中的本地图片改为相对路径./images/，并记录到images中
+func exportArticleMarkdown(article entity.Article, images map[string]struct{}) (string, error) {
+	relink := func(name string) (string, bool) {
+		localPath, ok := localImagePath(name)
+		if !ok {
+			return "", false
+		}
+		if info, err := os.Stat(localPath); err != nil || !info.Mode().IsRegular() {
+			return "", false
+		}
+		images[name] = struct{}{}
+		return "./" + imageDir + "/" + url.PathEscape(name), true
+	}
+
+	fm := &utils.FrontMatter{
+		Title:   article.Title,
+		Slug:    article.Slug,
+		Date:    articlePublishedAt(article).Format(utils.FrontMatterTimeLayout),
+		Updated: article.UpdatedAt.Format(utils.FrontMatterTimeLayout),
+	}
+	for _, tag := range article.Tags {
+		fm.Tags = append(fm.Tags, tag.Name)
+	}
+	// 自动生成的摘要与封面不导出，导入时会重新生成
+	if !article.SummaryAuto {
+		fm.Summary = article.Summary
+	}
+	if !article.CoverAuto {
+		fm.Cover = replaceLocalImages(article.CoverUrl, relink)
+	}
+	if article.Status != entity.ArticleStatusPublished {
+		fm.Status = article.Status
+	}
+	return utils.FormatFrontMatter(fm, replaceLocalImages(article.Content, relink))
+}
+
+// addFileToZip 将本地文件写入压缩包
+func addFileToZip(zw *zip.Writer, name, localPath string) error {
+	src, err := os.Open(localPath)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	dst, err := zw.Create(name)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(dst, src)
+	return err
+}
+
+// articleImporter 一次导入过程中的状态
+type articleImporter struct {
+	editor   string
+	images   map[string]*zip.File // 压缩包中的图片（按文件名）
+	imported map[string]string    // 已复制到本地的图片：压缩包中的文件名 -> 本地文件名
+	result   *dto.ArticleImportResult
+}
+
+// ImportArticles 从zip导入文章：每个Markdown文件（YAML元数据包含title、date、tags、summary、cover）创建一篇文章，
+// 不存在的标签会自动创建，正文中引用的压缩包内图片会复制到本地图片目录。单个文件失败不影响其他文件
+func ImportArticles(r io.ReaderAt, size int64, editor string) (*dto.ArticleImportResult, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, err
+	}
+
+	importer := &articleImporter{
+		editor:   editor,
+		images:   make(map[string]*zip.File),
+		imported: make(map[string]string),
+		result: &dto.ArticleImportResult{
+			Articles:    []dto.ArticleImportItem{},
+			Failed:      []dto.ArticleImportFailure{},
+			TagsCreated: []string{},
+		},
+	}
+	var docs []*zip.File
+	for _, f := range zr.File {
+		base := path.Base(f.Name)
+		if f.FileInfo().IsDir() || strings.HasPrefix(base, ".") || strings.HasPrefix(f.Name, "__MACOSX/") {
+			continue
+		}
+		switch ext := strings.ToLower(path.Ext(base)); ext {
+		case ".md", ".markdown":
+			docs = append(docs, f)
+		default:
+			if utils.IsAllowedImageExt(ext) && isSafeImageName(base) {
+				importer.images[base] = f
+			}
+		}
+	}
+	sort.Slice(docs, func(i, j int) bool { return docs[i].Name < docs[j].Name })
+
+	var ids []uint
+	for _, f := range docs {
+		item, err := importer.importFile(f)
+		if err != nil {
+			importer.result.Failed = append(importer.result.Failed, dto.ArticleImportFailure{File: f.Name, Error: err.Error()})
+			continue
+		}
+		importer.result.Articles = append(importer.result.Articles, *item)
+		ids = append(ids, item.ID)
+	}
+
+	importer.result.Imported = len(importer.result.Articles)
+	importer.result.Images = len(importer.imported)
+	if len(importer.result.TagsCreated) > 0 {
+		invalidateTagCache()
+	}
+	if len(ids) > 0 {
+		// 导入时修改了创建时间，列表与归档需要重新生成
+		invalidateArticleCache(ids...)
+	}
+	return importer.result, nil
+}
+
+// importFile 导入单个Markdown文件
+func (im *articleImporter) importFile(f *zip.File) (*dto.ArticleImportItem, error) {
+	data, err := readZipFile(f)
+	if err != nil {
+		return nil, err
+	}
+	fm, body, err := utils.ParseFrontMatter(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("解析元数据失败: %v", err)
+	}
+
+	title := strings.TrimSpace(fm.Title)
+	if title == "" {
+		title = strings.TrimSuffix(path.Base(f.Name), path.Ext(f.Name))
+	}
+	var date, updated *time.Time
+	if fm.Date != "" {
+		t, err := utils.ParseFrontMatterTime(fm.Date)
+		if err != nil {
+			return nil, err
+		}
+		date = &t
+	}
+	if fm.Updated != "" {
+		if t, err := utils.ParseFrontMatterTime(fm.Updated); err == nil {
+			updated = &t
+		}
+	}
+	summary := fm.Summary
+	if summary == "" {
+		summary = fm.Description
+	}
+
+	content, err := im.importImages(body)
+	if err != nil {
+		return nil, err
+	}
+	cover, err := im.importImages(fm.Cover)
+	if err != nil {
+		return nil, err
+	}
+	tagIds, err := im.findOrCreateTags(fm.Tags)
+	if err != nil {
+		return nil, err
+	}
+
+	article, err := CreateArticle(dto.ArticleCreateRequest{
+		Title:       title,
+		Slug:        fm.Slug,
+		Content:     content,
+		Summary:     summary,
+		CoverUrl:    cover,
+		TagIds:      tagIds,
+		Status:      fm.Status,
+		PublishedAt: date,
+	}, im.editor)
+	if err != nil {
+		return nil, err
+	}
+
+	// 保留原博客中的创建与更新时间
+	if date != nil {
+		if updated == nil {
+			updated = date
+		}
+		if err := config.DB.Model(&entity.Article{}).Where("id = ?", article.ID).
+			UpdateColumns(map[string]interface{}{"created_at": *date, "updated_at": *updated}).Error; err != nil {
+			log.Printf("设置导入文章 %d 的时间失败: %v", article.ID, err)
+		}
+	}
+	return &dto.ArticleImportItem{File: f.Name, ID: article.ID, Title: article.Title}, nil
+}
+
+// importImages 将文本中引用的压缩包内图片复制到本地图片目录，并改为上传接口返回的地址格式
+func (im *articleImporter) importImages(text string) (string, error) {
+	var copyErr error
+	text = replaceLocalImages(text, func(name string) (string, bool) {
+		f, ok := im.images[name]
+		if !ok || copyErr != nil {
+			return "", false
+		}
+		localName, ok := im.imported[name]
+		if !ok {
+			var err error
+			if localName, err = saveImportedImage(f); err != nil {
+				copyErr = fmt.Errorf("导入图片 %s 失败: %v", name, err)
+				return "", false
+			}
+			im.imported[name] = localName
+		}
+		return "api/" + imageDir + "/" + url.PathEscape(localName), true
+	})
+	return text, copyErr
+}
+
+// saveImportedImage 保存压缩包中的图片，本地已有同名且内容相同的文件时直接复用，
+// 内容不同时以内容哈希为前缀另存（仍冲突时再追加序号），不会覆盖已有文件
+func saveImportedImage(f *zip.File) (string, error) {
+	data, err := readZipFile(f)
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(imageDir, 0755); err != nil {
+		return "", err
+	}
+
+	base := path.Base(f.Name)
+	sum := sha256.Sum256(data)
+	hash := hex.EncodeToString(sum[:6])
+	for i := 0; i < 100; i++ {
+		name := base
+		if i == 1 {
+			name = hash + "_" + base
+		} else if i > 1 {
+			name = fmt.Sprintf("%s-%d_%s", hash, i, base)
+		}
+		localPath, ok := localImagePath(name)
+		if !ok {
+			return "", fmt.Errorf("无效的图片文件名: %s", base)
+		}
+
+		if existing, err := os.ReadFile(localPath); err == nil {
+			if bytes.Equal(existing, data) {
+				return name, nil
+			}
+			continue
+		}
+		// O_EXCL保证不会覆盖同时写入的同名文件
+		file, err := os.OpenFile(localPath, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+		if errors.Is(err, os.ErrExist) {
+			continue
+		}
+		if err != nil {
+			return "", err
+		}
+		_, err = file.Write(data)
+		if closeErr := file.Close(); err == nil {
+			err = closeErr
+		}
+		return name, err
+	}
+	return "", fmt.Errorf("图片 %s 的同名文件过多", base)
+}
+
+// findOrCreateTags 按名称查找标签，不存在时创建
+func (im *articleImporter) findOrCreateTags(names []string) ([]uint, error) {
+	var ids []uint
+	seen := make(map[string]struct{}, len(names))
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if _, ok := seen[name]; ok || name == "" {
+			continue
+		}
+		seen[name] = struct{}{}
+
+		var tag entity.Tag
+		err := config.DB.Where("name = ?", name).First(&tag).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			tag = entity.Tag{Name: name}
+			restored, err := restoreTagByName(&tag)
+			if err != nil {
+				return nil, err
+			}
+			if !restored {
+				if err := config.DB.Create(&tag).Error; err != nil {
+					return nil, fmt.Errorf("创建标签 %s 失败: %v", name, err)
+				}
+			}
+			im.result.TagsCreated = append(im.result.TagsCreated, name)
+		} else if err != nil {
+			return nil, err
+		}
+		ids = append(ids, tag.ID)
+	}
+	return ids, nil
+}
+
+// readZipFile 读取压缩包中的文件，超过大小限制时返回错误
+func readZipFile(f *zip.File) ([]byte, error) {
+	if f.UncompressedSize64 > importMaxEntrySize {
+		return nil, ErrImportFileTooLarge
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	data, err := io.ReadAll(io.LimitReader(rc, importMaxEntrySize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > importMaxEntrySize {
+		return nil, ErrImportFileTooLarge
+	}
+	return data, nil
+}
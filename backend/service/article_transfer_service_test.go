@@ -0,0 +1,139 @@
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestReplaceLocalImagesRejectsTraversal(t *testing.T) {
+	tests := []struct {
+		name   string
+		text   string
+		want   string
+		called []string
+	}{
+		{"普通图片", "![x](images/a.png)", "![x](NEW)", []string{"a.png"}},
+		{"api前缀", `<img src="/api/images/a%20b.png">`, `<img src="NEW">`, []string{"a b.png"}},
+		{"编码的斜杠", "![x](images/..%2F..%2Fetc%2Fpasswd)", "![x](images/..%2F..%2Fetc%2Fpasswd)", nil},
+		{"编码的反斜杠", "![x](images/..%5Cetc%5Cpasswd)", "![x](images/..%5Cetc%5Cpasswd)", nil},
+		{"双点", "![x](images/..)", "![x](images/..)", nil},
+		{"含双点的文件名", "![x](images/a..png)", "![x](images/a..png)", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var called []string
+			got := replaceLocalImages(tt.text, func(name string) (string, bool) {
+				called = append(called, name)
+				return "NEW", true
+			})
+			if got != tt.want {
+				t.Errorf("replaceLocalImages(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+			if len(called) != len(tt.called) {
+				t.Fatalf("replace called with %v, want %v", called, tt.called)
+			}
+			for i := range called {
+				if called[i] != tt.called[i] {
+					t.Errorf("replace called with %q, want %q", called[i], tt.called[i])
+				}
+			}
+		})
+	}
+}
+
+func TestLocalImagePath(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+		ok   bool
+	}{
+		{"a.png", filepath.Join(imageDir, "a.png"), true},
+		{"1700000000_cover.webp", filepath.Join(imageDir, "1700000000_cover.webp"), true},
+		{"", "", false},
+		{".", "", false},
+		{"..", "", false},
+		{"../../etc/passwd", "", false},
+		{"sub/a.png", "", false},
+		{`..\a.png`, "", false},
+		{"/etc/passwd", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := localImagePath(tt.name)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("localImagePath(%q) = %q, %v, want %q, %v", tt.name, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+// zipEntries 构造内存中的压缩包，返回其中的文件
+func zipEntries(t *testing.T, files ...[2]string) []*zip.File {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, file := range files {
+		w, err := zw.Create(file[0])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write([]byte(file[1])); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return zr.File
+}
+
+func TestSaveImportedImageDoesNotOverwrite(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+
+	files := zipEntries(t,
+		[2]string{"a/cover.png", "first"},
+		[2]string{"b/cover.png", "second"},
+		[2]string{"c/cover.png", "first"},
+	)
+	want := map[string]string{}
+	var names []string
+	for _, f := range files {
+		name, err := saveImportedImage(f)
+		if err != nil {
+			t.Fatalf("saveImportedImage(%s): %v", f.Name, err)
+		}
+		names = append(names, name)
+		data, _ := f.Open()
+		content := new(bytes.Buffer)
+		content.ReadFrom(data)
+		want[name] = content.String()
+	}
+
+	if names[0] != "cover.png" {
+		t.Errorf("第一张图片应使用原文件名, got %q", names[0])
+	}
+	if names[1] == names[0] {
+		t.Errorf("内容不同的同名图片不能覆盖已有文件, got %q", names[1])
+	}
+	if names[2] != names[0] {
+		t.Errorf("内容相同的图片应复用已有文件, got %q, want %q", names[2], names[0])
+	}
+	for name, content := range want {
+		data, err := os.ReadFile(filepath.Join(imageDir, name))
+		if err != nil || string(data) != content {
+			t.Errorf("%s 的内容为 %q（%v），want %q", name, data, err, content)
+		}
+	}
+}
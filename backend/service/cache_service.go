@@ -0,0 +1,178 @@
+package service
+
+import (
+	"backend/dto"
+	"backend/entity"
+	"backend/utils"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+)
+
+// 缓存采用旁路模式（cache-aside）：读取时先查Redis，未命中时回源数据库并写回缓存；写入数据库后使缓存失效。
+// 文章详情按ID缓存，写入后直接删除；列表缓存的键中带有版本号，写入后递增版本号，旧版本的缓存随TTL过期。
+
+// 缓存版本名称
+const (
+	cacheVersionArticles = "articles"
+	cacheVersionTags     = "tags"
+)
+
+const (
+	articleListCacheTTL    = 5 * time.Minute  // 文章列表缓存时间（阅读量、点赞数等计数最多延迟这么久）
+	tagListCacheTTL        = 30 * time.Minute // 标签列表缓存时间
+	cacheDoubleDeleteDelay = time.Second      // 延迟二次删除的等待时间
+)
+
+// flightCall 正在进行的回源调用
+type flightCall struct {
+	wg  sync.WaitGroup
+	val string
+	err error
+}
+
+// flightGroup 合并对同一个键的并发回源（single-flight），防止缓存失效瞬间大量请求同时查询数据库
+type flightGroup struct {
+	mu    sync.Mutex
+	calls map[string]*flightCall
+}
+
+var cacheFlight = &flightGroup{calls: make(map[string]*flightCall)}
+
+// do 执行回源函数，同一时间对同一个键只执行一次，其余调用等待并共享结果
+func (g *flightGroup) do(key string, fn func() (string, error)) (string, error) {
+	g.mu.Lock()
+	if call, ok := g.calls[key]; ok {
+		g.mu.Unlock()
+		call.wg.Wait()
+		return call.val, call.err
+	}
+	call := &flightCall{}
+	call.wg.Add(1)
+	g.calls[key] = call
+	g.mu.Unlock()
+
+	call.val, call.err = fn()
+	call.wg.Done()
+
+	g.mu.Lock()
+	delete(g.calls, key)
+	g.mu.Unlock()
+	return call.val, call.err
+}
+
+// loadWithCache 按缓存旁路模式读取数据：命中时直接解析缓存，未命中时合并并发请求回源并写回缓存。
+// 回源结果以JSON共享，每个调用方各自解析出独立的副本，互不影响
+func loadWithCache(key string, ttl time.Duration, dest interface{}, load func() (interface{}, error)) error {
+	redisService := &RedisService{}
+	if cached, err := redisService.GetCache(key); err == nil && cached != "" {
+		if err := utils.ParseJSONString(cached, dest); err == nil {
+			return nil
+		}
+	}
+
+	content, err := cacheFlight.do(key, func() (string, error) {
+		value, err := load()
+		if err != nil {
+			return "", err
+		}
+		content := utils.ToJSONString(value)
+		if err := redisService.SetCache(key, content, ttl); err != nil {
+			log.Printf("写入缓存 %s 失败: %v", key, err)
+		}
+		return content, nil
+	})
+	if err != nil {
+		return err
+	}
+	return utils.ParseJSONString(content, dest)
+}
+
+// cacheVersion 获取缓存版本号，Redis不可用时返回0（此时缓存读写均会失败并直接回源）
+func cacheVersion(name string) int64 {
+	redisService := &RedisService{}
+	version, err := redisService.GetCacheVersion(name)
+	if err != nil {
+		return 0
+	}
+	return version
+}
+
+// articleListCacheKey 文章列表缓存键：当前版本号 + 查询条件摘要
+func articleListCacheKey(q dto.ArticleListQuery) string {
+	sum := sha1.Sum([]byte(utils.ToJSONString(q)))
+	return fmt.Sprintf(ArticleListKey, cacheVersion(cacheVersionArticles), hex.EncodeToString(sum[:]))
+}
+
+// GetPublicArticle 获取已发布文章的详情，返回是否命中缓存。
+// 热门文章（阅读量>100）缓存在Redis中，缓存未命中时对同一篇文章的并发请求只查询一次数据库
+func GetPublicArticle(id uint) (*dto.ArticleResponse, bool, error) {
+	redisService := &RedisService{}
+	var article dto.ArticleResponse
+	if cached, err := redisService.GetCachedArticleContent(id); err == nil && cached != "" {
+		// 旧的缓存可能没有渲染结果，此时回源数据库
+		if err := utils.ParseJSONString(cached, &article); err == nil && article.Status == entity.ArticleStatusPublished &&
+			(article.Html != "" || article.Content == "") {
+			return &article, true, nil
+		}
+	}
+
+	content, err := cacheFlight.do(fmt.Sprintf(ArticleContentKey, id), func() (string, error) {
+		resp, err := GetArticleByID(id)
+		if err != nil {
+			return "", err
+		}
+		if resp.Status != entity.ArticleStatusPublished {
+			return "", ErrArticleNotPublic
+		}
+		content := utils.ToJSONString(resp)
+		if shouldCache, err := redisService.ShouldCacheArticle(id); err == nil && shouldCache {
+			if err := redisService.CacheArticleContent(id, content); err != nil {
+				log.Printf("缓存文章 %d 内容失败: %v", id, err)
+			}
+		}
+		return content, nil
+	})
+	if err != nil {
+		return nil, false, err
+	}
+
+	article = dto.ArticleResponse{}
+	if err := utils.ParseJSONString(content, &article); err != nil {
+		return nil, false, err
+	}
+	return &article, false, nil
+}
+
+// invalidateArticleCache 文章写入后删除其详情缓存，并使文章列表缓存失效
+func invalidateArticleCache(articleIDs ...uint) {
+	redisService := &RedisService{}
+	if err := redisService.DeleteArticleContent(articleIDs...); err != nil {
+		log.Printf("删除文章内容缓存失败: %v", err)
+	}
+	if err := redisService.BumpCacheVersion(cacheVersionArticles); err != nil {
+		log.Printf("更新文章列表缓存版本失败: %v", err)
+	}
+
+	// 写入期间并发读取的请求可能把旧数据重新写入缓存，稍后再删除一次
+	if len(articleIDs) > 0 {
+		time.AfterFunc(cacheDoubleDeleteDelay, func() {
+			if err := redisService.DeleteArticleContent(articleIDs...); err != nil {
+				log.Printf("删除文章内容缓存失败: %v", err)
+			}
+		})
+	}
+}
+
+// invalidateTagCache 标签变化后使标签列表与文章列表（按标签筛选的结果）缓存失效
+func invalidateTagCache() {
+	redisService := &RedisService{}
+	for _, name := range []string{cacheVersionTags, cacheVersionArticles} {
+		if err := redisService.BumpCacheVersion(name); err != nil {
+			log.Printf("更新缓存版本 %s 失败: %v", name, err)
+		}
+	}
+}
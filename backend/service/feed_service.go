@@ -0,0 +1,330 @@
+package service
+
+import (
+	"backend/config"
+	"backend/entity"
+	"encoding/xml"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+)
+
+// 订阅源格式
+const (
+	FeedFormatAtom = "atom"
+	FeedFormatRSS  = "rss"
+)
+
+// atomFeed Atom 1.0 订阅源
+type atomFeed struct {
+	XMLName xml.Name    `xml:"feed"`
+	Xmlns   string      `xml:"xmlns,attr"`
+	Title   string      `xml:"title"`
+	ID      string      `xml:"id"`
+	Updated string      `xml:"updated"`
+	Author  atomAuthor  `xml:"author"`
+	Links   []atomLink  `xml:"link"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type atomAuthor struct {
+	Name string `xml:"name"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr,omitempty"`
+	Type string `xml:"type,attr,omitempty"`
+}
+
+type atomText struct {
+	Type string `xml:"type,attr"`
+	Body string `xml:",chardata"`
+}
+
+type atomCategory struct {
+	Term string `xml:"term,attr"`
+}
+
+type atomEntry struct {
+	Title      string         `xml:"title"`
+	ID         string         `xml:"id"`
+	Link       atomLink       `xml:"link"`
+	Published  string         `xml:"published"`
+	Updated    string         `xml:"updated"`
+	Summary    *atomText      `xml:"summary,omitempty"`
+	Content    *atomText      `xml:"content,omitempty"`
+	Categories []atomCategory `xml:"category"`
+}
+
+// rssFeed RSS 2.0 订阅源
+type rssFeed struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	Description   string    `xml:"description"`
+	LastBuildDate string    `xml:"lastBuildDate"`
+	Items         []rssItem `xml:"item"`
+}
+
+type rssGuid struct {
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
+}
+
+type rssItem struct {
+	Title       string   `xml:"title"`
+	Link        string   `xml:"link"`
+	Guid        rssGuid  `xml:"guid"`
+	PubDate     string   `xml:"pubDate"`
+	Description string   `xml:"description"`
+	Categories  []string `xml:"category"`
+}
+
+// sitemapURLSet 站点地图
+type sitemapURLSet struct {
+	XMLName xml.Name     `xml:"urlset"`
+	Xmlns   string       `xml:"xmlns,attr"`
+	URLs    []sitemapURL `xml:"url"`
+}
+
+type sitemapURL struct {
+	Loc     string `xml:"loc"`
+	LastMod string `xml:"lastmod,omitempty"`
+}
+
+// siteURL 前台站点地址（用于生成文章、标签、题目的链接）
+func siteURL() string {
+	url := os.Getenv("SITE_URL")
+	if url == "" {
+		url = "http://localhost:5173"
+	}
+	return strings.TrimRight(url, "/")
+}
+
+// siteTitle 站点名称
+func siteTitle() string {
+	if title := os.Getenv("SITE_TITLE"); title != "" {
+		return title
+	}
+	return "IMISLab"
+}
+
+// articleURL 文章在前台的访问地址
+func articleURL(id uint) string {
+	return fmt.Sprintf("%s/article/%d", siteURL(), id)
+}
+
+// GetFeed 生成最新已发布文章的订阅源（Atom或RSS），tagID不为0时只包含该标签的文章，full为true时输出全文HTML
+func GetFeed(format string, tagID uint, full bool) (string, error) {
+	cacheName := fmt.Sprintf("%s:%d:%t", format, tagID, full)
+	redisService := &RedisService{}
+	if cached, err := redisService.GetCachedFeed(cacheName); err == nil && cached != "" {
+		return cached, nil
+	}
+
+	title := siteTitle()
+	selfPath := "/feed.xml"
+	if format == FeedFormatRSS {
+		selfPath = "/rss.xml"
+	}
+	if tagID != 0 {
+		var tag entity.Tag
+		if err := config.DB.First(&tag, tagID).Error; err != nil {
+			return "", err
+		}
+		title = fmt.Sprintf("%s - %s", siteTitle(), tag.Name)
+		selfPath += fmt.Sprintf("?tag=%d", tagID)
+	}
+
+	query := config.DB.Preload("Tags").Where("status = ?", entity.ArticleStatusPublished)
+	if tagID != 0 {
+		query = query.Where("id IN (?)", config.DB.Table("article_tags").Select("article_id").Where("tag_id = ?", tagID))
+	}
+	var articles []entity.Article
+	if err := query.Order("published_at desc").Limit(getEnvInt("FEED_ITEM_LIMIT", 20)).Find(&articles).Error; err != nil {
+		return "", err
+	}
+	if full {
+		for i := range articles {
+			ensureArticleRendered(&articles[i])
+		}
+	}
+
+	var doc interface{}
+	if format == FeedFormatRSS {
+		doc = buildRSSFeed(title, articles, full)
+	} else {
+		doc = buildAtomFeed(title, siteURL()+selfPath, articles, full)
+	}
+	content, err := marshalXML(doc)
+	if err != nil {
+		return "", err
+	}
+	if err := redisService.CacheFeed(cacheName, content); err != nil {
+		log.Printf("缓存订阅源失败: %v", err)
+	}
+	return content, nil
+}
+
+// buildAtomFeed 构造Atom订阅源
+func buildAtomFeed(title, selfURL string, articles []entity.Article, full bool) atomFeed {
+	feed := atomFeed{
+		Xmlns:   "http://www.w3.org/2005/Atom",
+		Title:   title,
+		ID:      selfURL,
+		Updated: feedUpdatedAt(articles).Format(time.RFC3339),
+		Author:  atomAuthor{Name: siteTitle()},
+		Links: []atomLink{
+			{Href: selfURL, Rel: "self", Type: "application/atom+xml"},
+			{Href: siteURL(), Rel: "alternate", Type: "text/html"},
+		},
+	}
+	for _, article := range articles {
+		link := articleURL(article.ID)
+		entry := atomEntry{
+			Title:     article.Title,
+			ID:        link,
+			Link:      atomLink{Href: link, Rel: "alternate", Type: "text/html"},
+			Published: articlePublishedAt(article).Format(time.RFC3339),
+			Updated:   article.UpdatedAt.Format(time.RFC3339),
+			Summary:   &atomText{Type: "text", Body: article.Summary},
+		}
+		if full {
+			entry.Content = &atomText{Type: "html", Body: article.ContentHTML}
+		}
+		for _, tag := range article.Tags {
+			entry.Categories = append(entry.Categories, atomCategory{Term: tag.Name})
+		}
+		feed.Entries = append(feed.Entries, entry)
+	}
+	return feed
+}
+
+// buildRSSFeed 构造RSS 2.0订阅源
+func buildRSSFeed(title string, articles []entity.Article, full bool) rssFeed {
+	channel := rssChannel{
+		Title:         title,
+		Link:          siteURL(),
+		Description:   title + " 最新文章",
+		LastBuildDate: feedUpdatedAt(articles).Format(time.RFC1123Z),
+	}
+	for _, article := range articles {
+		link := articleURL(article.ID)
+		item := rssItem{
+			Title:       article.Title,
+			Link:        link,
+			Guid:        rssGuid{IsPermaLink: true, Value: link},
+			PubDate:     articlePublishedAt(article).Format(time.RFC1123Z),
+			Description: article.Summary,
+		}
+		if full {
+			item.Description = article.ContentHTML
+		}
+		for _, tag := range article.Tags {
+			item.Categories = append(item.Categories, tag.Name)
+		}
+		channel.Items = append(channel.Items, item)
+	}
+	return rssFeed{Version: "2.0", Channel: channel}
+}
+
+// GetSitemap 生成包含文章、标签与OJ题目的站点地图
+func GetSitemap() (string, error) {
+	redisService := &RedisService{}
+	if cached, err := redisService.GetCachedFeed("sitemap"); err == nil && cached != "" {
+		return cached, nil
+	}
+
+	site := siteURL()
+	urlSet := sitemapURLSet{Xmlns: "http://www.sitemaps.org/schemas/sitemap/0.9"}
+	for _, path := range []string{"/", "/article-list", "/oj-list", "/about"} {
+		urlSet.URLs = append(urlSet.URLs, sitemapURL{Loc: site + path})
+	}
+
+	var articles []entity.Article
+	if err := config.DB.Select("id", "updated_at").Where("status = ?", entity.ArticleStatusPublished).
+		Order("id").Find(&articles).Error; err != nil {
+		return "", err
+	}
+	for _, article := range articles {
+		urlSet.URLs = append(urlSet.URLs, sitemapURL{Loc: articleURL(article.ID), LastMod: article.UpdatedAt.Format(time.RFC3339)})
+	}
+
+	var tags []entity.Tag
+	if err := config.DB.Select("id", "updated_at").Order("id").Find(&tags).Error; err != nil {
+		return "", err
+	}
+	for _, tag := range tags {
+		urlSet.URLs = append(urlSet.URLs, sitemapURL{
+			Loc:     fmt.Sprintf("%s/article-list?tagIds=%d", site, tag.ID),
+			LastMod: tag.UpdatedAt.Format(time.RFC3339),
+		})
+	}
+
+	var problems []entity.OJProblem
+	if err := config.DB.Select("id", "updated_at").Order("id").Find(&problems).Error; err != nil {
+		return "", err
+	}
+	for _, problem := range problems {
+		urlSet.URLs = append(urlSet.URLs, sitemapURL{
+			Loc:     fmt.Sprintf("%s/oj/%d", site, problem.ID),
+			LastMod: problem.UpdatedAt.Format(time.RFC3339),
+		})
+	}
+
+	content, err := marshalXML(urlSet)
+	if err != nil {
+		return "", err
+	}
+	if err := redisService.CacheFeed("sitemap", content); err != nil {
+		log.Printf("缓存站点地图失败: %v", err)
+	}
+	return content, nil
+}
+
+// invalidateFeedCache 文章、标签或题目变化后清除订阅源与站点地图缓存
+func invalidateFeedCache() {
+	redisService := &RedisService{}
+	if err := redisService.InvalidateFeedCache(); err != nil {
+		log.Printf("清除订阅源缓存失败: %v", err)
+	}
+}
+
+// feedUpdatedAt 订阅源的更新时间：文章中最近的更新时间
+func feedUpdatedAt(articles []entity.Article) time.Time {
+	var updated time.Time
+	for _, article := range articles {
+		if article.UpdatedAt.After(updated) {
+			updated = article.UpdatedAt
+		}
+	}
+	if updated.IsZero() {
+		updated = time.Now()
+	}
+	return updated
+}
+
+// articlePublishedAt 文章发布时间，历史文章没有发布时间时使用创建时间
+func articlePublishedAt(article entity.Article) time.Time {
+	if article.PublishedAt != nil {
+		return *article.PublishedAt
+	}
+	return article.CreatedAt
+}
+
+// marshalXML 序列化为带XML声明的文档
+func marshalXML(doc interface{}) (string, error) {
+	data, err := xml.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return xml.Header + string(data), nil
+}
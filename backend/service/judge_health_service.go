@@ -0,0 +1,327 @@
+package service
+
+import (
+	"backend/config"
+	"backend/dto"
+	"backend/entity"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// 熔断器状态
+const (
+	BreakerClosed   = "CLOSED"    // 正常放行
+	BreakerOpen     = "OPEN"      // 熔断中，拒绝提交
+	BreakerHalfOpen = "HALF_OPEN" // 冷却结束，只放行一个探测请求
+)
+
+// 提交记录的派发状态
+const (
+	SubmissionStatusWaiting        = "等待中"
+	SubmissionStatusDispatchFailed = "DISPATCH_FAILED"
+)
+
+// ErrJudgeUnavailable 评测服务不可用（熔断中）
+var ErrJudgeUnavailable = errors.New("评测服务暂不可用，请稍后再试")
+
+// judgeHTTPClient 访问Judge0使用的HTTP客户端，避免无限期阻塞
+var judgeHTTPClient = &http.Client{Timeout: 10 * time.Second}
+
+// judgeBreaker Judge0熔断器及健康状态
+type judgeBreaker struct {
+	mu                  sync.RWMutex
+	probing             int32 // 半开状态下是否已有探测请求在进行（1表示有）
+	state               string
+	consecutiveFailures int
+	openedAt            time.Time
+	lastCheckAt         time.Time
+	lastSuccessAt       time.Time
+	lastError           string
+	version             string
+	workers             []dto.Judge0WorkerStatus
+}
+
+var breaker = &judgeBreaker{state: BreakerClosed}
+
+// breakerThreshold 连续失败多少次后熔断
+func breakerThreshold() int {
+	return getEnvInt("JUDGE_BREAKER_THRESHOLD", 3)
+}
+
+// breakerCooldown 熔断后多久进入半开状态
+func breakerCooldown() time.Duration {
+	return time.Duration(getEnvInt("JUDGE_BREAKER_COOLDOWN_SECONDS", 30)) * time.Second
+}
+
+// advanceBreakerLocked 冷却结束后由熔断切换到半开，调用方需持有写锁
+func advanceBreakerLocked() {
+	if breaker.state == BreakerOpen && time.Since(breaker.openedAt) >= breakerCooldown() {
+		breaker.state = BreakerHalfOpen
+		atomic.StoreInt32(&breaker.probing, 0)
+	}
+}
+
+// AllowJudgeDispatch 判断当前是否允许向Judge0派发提交，半开状态下只放行一个探测请求
+func AllowJudgeDispatch() bool {
+	breaker.mu.Lock()
+	defer breaker.mu.Unlock()
+
+	advanceBreakerLocked()
+	switch breaker.state {
+	case BreakerClosed:
+		return true
+	case BreakerHalfOpen:
+		return atomic.CompareAndSwapInt32(&breaker.probing, 0, 1)
+	default:
+		return false
+	}
+}
+
+// releaseJudgeProbe 探测请求未调用Judge0就结束时（如题目不存在）释放名额，让后续请求继续探测
+func releaseJudgeProbe() {
+	breaker.mu.RLock()
+	defer breaker.mu.RUnlock()
+
+	if breaker.state == BreakerHalfOpen {
+		atomic.StoreInt32(&breaker.probing, 0)
+	}
+}
+
+// recordJudgeSuccess 记录一次成功的Judge0调用
+func recordJudgeSuccess() {
+	breaker.mu.Lock()
+	defer breaker.mu.Unlock()
+
+	if breaker.state != BreakerClosed {
+		log.Printf("Judge0已恢复，熔断器关闭")
+	}
+	breaker.state = BreakerClosed
+	atomic.StoreInt32(&breaker.probing, 0)
+	breaker.consecutiveFailures = 0
+	breaker.lastSuccessAt = time.Now()
+	breaker.lastError = ""
+}
+
+// recordJudgeFailure 记录一次失败的Judge0调用，达到阈值后熔断
+func recordJudgeFailure(err error) {
+	breaker.mu.Lock()
+	defer breaker.mu.Unlock()
+
+	breaker.consecutiveFailures++
+	breaker.lastError = err.Error()
+
+	// 半开状态下探测失败立即重新熔断
+	if breaker.state == BreakerHalfOpen || breaker.consecutiveFailures >= breakerThreshold() {
+		if breaker.state != BreakerOpen {
+			log.Printf("Judge0连续失败%d次，熔断器打开: %v", breaker.consecutiveFailures, err)
+		}
+		breaker.state = BreakerOpen
+		breaker.openedAt = time.Now()
+		atomic.StoreInt32(&breaker.probing, 0)
+	}
+}
+
+// GetJudgeHealth 获取Judge0健康状态
+func GetJudgeHealth() dto.JudgeHealthResponse {
+	// 先触发一次状态迁移，保证返回的状态是最新的（不占用半开状态的探测名额）
+	breaker.mu.Lock()
+	defer breaker.mu.Unlock()
+	advanceBreakerLocked()
+
+	resp := dto.JudgeHealthResponse{
+		Healthy:             breaker.state == BreakerClosed,
+		BreakerState:        breaker.state,
+		ConsecutiveFailures: breaker.consecutiveFailures,
+		LastError:           breaker.lastError,
+		Version:             breaker.version,
+		Workers:             breaker.workers,
+	}
+	if !breaker.lastCheckAt.IsZero() {
+		resp.LastCheckAt = breaker.lastCheckAt.Format("2006-01-02 15:04:05")
+	}
+	if !breaker.lastSuccessAt.IsZero() {
+		resp.LastSuccessAt = breaker.lastSuccessAt.Format("2006-01-02 15:04:05")
+	}
+	return resp
+}
+
+// StartJudgeHealthCheckTask 启动Judge0健康检查与失败提交清理任务
+func StartJudgeHealthCheckTask() {
+	interval := time.Duration(getEnvInt("JUDGE_HEALTH_INTERVAL_SECONDS", 30)) * time.Second
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	log.Printf("启动Judge0健康检查任务，每%v执行一次", interval)
+
+	checkJudgeHealth()
+	for range ticker.C {
+		checkJudgeHealth()
+		if err := cleanupUndispatchedSubmissions(); err != nil {
+			log.Printf("清理派发失败的提交记录失败: %v", err)
+		}
+	}
+}
+
+// checkJudgeHealth 探测Judge0的 /about 与 /workers 接口
+func checkJudgeHealth() {
+	baseURL := getJudge0BaseURL()
+
+	var about dto.Judge0AboutResponse
+	err := getJudgeJSON(baseURL+"/about", &about)
+
+	var workers []dto.Judge0WorkerStatus
+	if err == nil {
+		err = getJudgeJSON(baseURL+"/workers", &workers)
+	}
+	if err == nil && len(workers) > 0 {
+		available := 0
+		for _, w := range workers {
+			available += w.Available
+		}
+		if available == 0 {
+			err = fmt.Errorf("Judge0没有可用的评测worker")
+		}
+	}
+
+	breaker.mu.Lock()
+	breaker.lastCheckAt = time.Now()
+	if err == nil {
+		breaker.version = about.Version
+		breaker.workers = workers
+	}
+	breaker.mu.Unlock()
+
+	if err != nil {
+		recordJudgeFailure(err)
+		return
+	}
+	recordJudgeSuccess()
+}
+
+// getJudgeJSON 请求Judge0接口并解析JSON
+func getJudgeJSON(url string, out interface{}) error {
+	resp, err := judgeHTTPClient.Get(url)
+	if err != nil {
+		return fmt.Errorf("请求%s失败: %v", url, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("请求%s返回状态码: %d", url, resp.StatusCode)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("解析%s响应失败: %v", url, err)
+	}
+	return nil
+}
+
+// cleanupUndispatchedSubmissions 清理未能派发到Judge0的提交记录
+func cleanupUndispatchedSubmissions() error {
+	// 长时间停留在"等待中"的记录说明派发过程异常中断，标记为派发失败
+	staleBefore := time.Now().Add(-5 * time.Minute)
+	if err := config.DB.Model(&entity.Submission{}).
+		Where("status = ? AND judge_token = '' AND submit_time < ?", SubmissionStatusWaiting, staleBefore).
+		Update("status", SubmissionStatusDispatchFailed).Error; err != nil {
+		return err
+	}
+
+	// Judge0恢复后先重新派发仍在保留期内的记录，再删除过期的记录
+	retention := time.Duration(getEnvInt("JUDGE_FAILED_RETENTION_HOURS", 24)) * time.Hour
+	expireBefore := time.Now().Add(-retention)
+	if err := redispatchFailedSubmissions(expireBefore); err != nil {
+		log.Printf("重新派发提交记录失败: %v", err)
+	}
+
+	// 派发失败的记录保留一段时间供排查，之后删除
+	return config.DB.
+		Where("status = ? AND submit_time < ?", SubmissionStatusDispatchFailed, expireBefore).
+		Delete(&entity.Submission{}).Error
+}
+
+// redispatchFailedSubmissions 熔断器关闭时把submitAfter之后派发失败的提交重新派发到Judge0，每次最多处理一批
+func redispatchFailedSubmissions(submitAfter time.Time) error {
+	breaker.mu.RLock()
+	closed := breaker.state == BreakerClosed
+	breaker.mu.RUnlock()
+	if !closed {
+		return nil
+	}
+
+	var submissions []entity.Submission
+	if err := config.DB.
+		Where("status = ? AND judge_token = '' AND submit_time >= ?", SubmissionStatusDispatchFailed, submitAfter).
+		Order("id asc").Limit(getEnvInt("JUDGE_REDISPATCH_BATCH", 20)).
+		Find(&submissions).Error; err != nil {
+		return err
+	}
+
+	judge0URL := getJudge0URL()
+	dispatched := 0
+	for i := range submissions {
+		submission := &submissions[i]
+		var testcase entity.OJTestcase
+		if err := config.DB.Where("problem_id = ?", submission.ProblemID).First(&testcase).Error; err != nil {
+			// 题目或用例已被删除，留给保留期结束后清理
+			continue
+		}
+
+		token, err := dispatchToJudge0(judge0URL, submission, testcase)
+		if err != nil {
+			// Judge0再次失败时停止本轮，剩余的记录等下次恢复后处理
+			recordJudgeFailure(err)
+			return err
+		}
+		recordJudgeSuccess()
+
+		// 只有仍处于派发失败状态的记录才更新，避免覆盖并发的修改
+		result := config.DB.Model(&entity.Submission{}).
+			Where("id = ? AND status = ?", submission.ID, SubmissionStatusDispatchFailed).
+			Updates(map[string]interface{}{"judge_token": token, "status": "IN_QUEUE"})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			continue
+		}
+		submission.JudgeToken = token
+		submission.Status = "IN_QUEUE"
+		go pollJudgeResult(submission, judge0URL)
+		dispatched++
+	}
+	if dispatched > 0 {
+		log.Printf("已重新派发%d条派发失败的提交记录", dispatched)
+	}
+	return nil
+}
+
+// getJudge0URL 获取Judge0提交接口地址
+func getJudge0URL() string {
+	judge0URL := os.Getenv("JUDGE0_URL")
+	if judge0URL == "" {
+		// 使用配置的默认Judge0服务地址
+		judge0URL = "http://47.92.90.228:2358/submissions"
+	}
+	return judge0URL
+}
+
+// getJudge0BaseURL 获取Judge0服务根地址
+func getJudge0BaseURL() string {
+	return strings.TrimSuffix(strings.TrimRight(getJudge0URL(), "/"), "/submissions")
+}
+
+// getEnvInt 读取整型环境变量，不存在或非法时返回默认值
+func getEnvInt(key string, defaultValue int) int {
+	if value, err := strconv.Atoi(os.Getenv(key)); err == nil && value > 0 {
+		return value
+	}
+	return defaultValue
+}
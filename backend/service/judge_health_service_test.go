@@ -0,0 +1,102 @@
+package service
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// setBreaker 把熔断器设置为指定状态
+func setBreaker(state string, openedAt time.Time) {
+	breaker.mu.Lock()
+	defer breaker.mu.Unlock()
+	breaker.state = state
+	breaker.openedAt = openedAt
+	breaker.consecutiveFailures = 0
+	atomic.StoreInt32(&breaker.probing, 0)
+}
+
+// resetBreaker 把熔断器设置为指定状态，测试结束后还原为关闭
+func resetBreaker(t *testing.T, state string, openedAt time.Time) {
+	t.Helper()
+	setBreaker(state, openedAt)
+	t.Cleanup(func() { setBreaker(BreakerClosed, time.Time{}) })
+}
+
+// countAllowed 并发调用AllowJudgeDispatch，返回放行的次数
+func countAllowed(n int) int {
+	var allowed int32
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if AllowJudgeDispatch() {
+				atomic.AddInt32(&allowed, 1)
+			}
+		}()
+	}
+	wg.Wait()
+	return int(allowed)
+}
+
+func TestAllowJudgeDispatch(t *testing.T) {
+	tests := []struct {
+		name     string
+		state    string
+		openedAt time.Time
+		want     int
+	}{
+		{"关闭时全部放行", BreakerClosed, time.Time{}, 50},
+		{"冷却中全部拒绝", BreakerOpen, time.Now(), 0},
+		{"冷却结束只放行一个探测", BreakerOpen, time.Now().Add(-time.Hour), 1},
+		{"半开状态只放行一个探测", BreakerHalfOpen, time.Time{}, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resetBreaker(t, tt.state, tt.openedAt)
+			if got := countAllowed(50); got != tt.want {
+				t.Errorf("放行了%d个请求, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestJudgeProbeLifecycle(t *testing.T) {
+	resetBreaker(t, BreakerOpen, time.Now().Add(-time.Hour))
+
+	// 查询健康状态会触发状态迁移，但不占用探测名额
+	if state := GetJudgeHealth().BreakerState; state != BreakerHalfOpen {
+		t.Fatalf("冷却结束后应进入半开, got %s", state)
+	}
+	if !AllowJudgeDispatch() {
+		t.Fatal("查询健康状态后仍应放行一个探测")
+	}
+	if AllowJudgeDispatch() {
+		t.Fatal("探测进行中不应再放行")
+	}
+
+	// 探测未调用Judge0就结束时归还名额
+	releaseJudgeProbe()
+	if !AllowJudgeDispatch() {
+		t.Fatal("归还名额后应放行下一个探测")
+	}
+
+	// 探测失败重新熔断
+	recordJudgeFailure(errors.New("timeout"))
+	if GetJudgeHealth().BreakerState != BreakerOpen || AllowJudgeDispatch() {
+		t.Fatal("探测失败后应重新熔断")
+	}
+
+	// 探测成功后关闭熔断器，全部放行
+	resetBreaker(t, BreakerHalfOpen, time.Time{})
+	if !AllowJudgeDispatch() {
+		t.Fatal("半开状态应放行一个探测")
+	}
+	recordJudgeSuccess()
+	if got := countAllowed(10); got != 10 {
+		t.Errorf("探测成功后放行了%d个请求, want 10", got)
+	}
+}
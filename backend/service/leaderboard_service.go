@@ -0,0 +1,401 @@
+package service
+
+import (
+	"backend/config"
+	"backend/dto"
+	"backend/entity"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"gorm.io/gorm"
+)
+
+// 排行榜周期
+const (
+	LeaderboardAll   = "all"
+	LeaderboardWeek  = "week"
+	LeaderboardMonth = "month"
+)
+
+// leaderboardScoreBase 排行榜分数 = 通过题数 * base - 提交次数，通过题数相同时提交越少越靠前
+const leaderboardScoreBase = 1e7
+
+// verdictStatuses 已给出评测结论的提交状态（参与排行榜统计）
+var verdictStatuses = []string{
+	"ACCEPTED", "WRONG_ANSWER", "TIME_LIMIT_EXCEEDED",
+	"COMPILATION_ERROR", "RUNTIME_ERROR", "MEMORY_LIMIT_EXCEEDED",
+}
+
+// IsValidLeaderboardPeriod 判断排行榜周期是否合法
+func IsValidLeaderboardPeriod(period string) bool {
+	return period == LeaderboardAll || period == LeaderboardWeek || period == LeaderboardMonth
+}
+
+// leaderboardWindow 计算周期对应的Redis键后缀、起始时间与键过期时间
+func leaderboardWindow(period string, t time.Time) (suffix string, start time.Time, ttl time.Duration) {
+	switch period {
+	case LeaderboardWeek:
+		year, week := t.ISOWeek()
+		offset := (int(t.Weekday()) + 6) % 7 // 周一为一周的开始
+		start = time.Date(t.Year(), t.Month(), t.Day()-offset, 0, 0, 0, 0, t.Location())
+		return fmt.Sprintf("week:%d-W%02d", year, week), start, 14 * 24 * time.Hour
+	case LeaderboardMonth:
+		start = time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, t.Location())
+		return fmt.Sprintf("month:%s", t.Format("2006-01")), start, 62 * 24 * time.Hour
+	default:
+		return LeaderboardAll, time.Time{}, 0
+	}
+}
+
+var (
+	displayIDSalt     []byte
+	displayIDSaltOnce sync.Once
+)
+
+// leaderboardDisplayIDSalt 匿名标识使用的盐：优先读取OJ_DISPLAY_ID_SALT，未配置时随机生成（重启后标识会变化）
+func leaderboardDisplayIDSalt() []byte {
+	displayIDSaltOnce.Do(func() {
+		if salt := os.Getenv("OJ_DISPLAY_ID_SALT"); salt != "" {
+			displayIDSalt = []byte(salt)
+			return
+		}
+		displayIDSalt = make([]byte, 32)
+		if _, err := rand.Read(displayIDSalt); err != nil {
+			log.Printf("生成排行榜匿名标识的盐失败: %v", err)
+		}
+		log.Println("未配置OJ_DISPLAY_ID_SALT，排行榜匿名标识在重启后会变化")
+	})
+	return displayIDSalt
+}
+
+// leaderboardDisplayID 提交者在排行榜上的匿名标识，提交者标识默认是客户端IP，不能直接返回
+func leaderboardDisplayID(submitter string) string {
+	mac := hmac.New(sha256.New, leaderboardDisplayIDSalt())
+	mac.Write([]byte(submitter))
+	return "u-" + hex.EncodeToString(mac.Sum(nil))[:12]
+}
+
+// leaderboardScore 计算排行榜分数
+func leaderboardScore(solved, submissions int64) float64 {
+	return float64(solved)*leaderboardScoreBase - float64(submissions)
+}
+
+// leaderboardRebuildTTL 重建标记的过期时间，重建异常中断时不会一直暂存判题结果
+const leaderboardRebuildTTL = 5 * time.Minute
+
+// leaderboardVerdictScript 原子地把一次判题结果计入排行榜：正在重建时按提交ID暂存，由重建完成后补记，返回2；
+// 排行榜未构建时不处理（首次查询时从数据库构建），返回0。ARGV[6]为1时跳过检查直接计入（用于补记）。
+// KEYS: 构建标记、排行榜、用户通过的题目集合、提交次数、重建标记、暂存的判题结果
+// ARGV: 用户、通过的题目ID（未通过为空）、分数基数、过期秒数、提交ID、是否直接计入
+var leaderboardVerdictScript = redis.NewScript(`
+if ARGV[6] ~= '1' then
+	if redis.call('EXISTS', KEYS[5]) == 1 then
+		redis.call('HSET', KEYS[6], ARGV[5], ARGV[2] .. '|' .. ARGV[1])
+		redis.call('EXPIRE', KEYS[6], redis.call('TTL', KEYS[5]))
+		return 2
+	end
+	if redis.call('EXISTS', KEYS[1]) == 0 then
+		return 0
+	end
+end
+if ARGV[2] ~= '' then
+	redis.call('SADD', KEYS[3], ARGV[2])
+end
+local submits = redis.call('HINCRBY', KEYS[4], ARGV[1], 1)
+local solved = redis.call('SCARD', KEYS[3])
+redis.call('ZADD', KEYS[2], solved * tonumber(ARGV[3]) - submits, ARGV[1])
+if tonumber(ARGV[4]) > 0 then
+	redis.call('EXPIRE', KEYS[3], ARGV[4])
+end
+return 1
+`)
+
+// runLeaderboardVerdict 执行leaderboardVerdictScript，force为true时直接计入
+func runLeaderboardVerdict(suffix string, ttl time.Duration, submissionID uint, submitter, solvedProblem string, force bool) error {
+	keys := []string{
+		fmt.Sprintf(OJLbBuiltKey, suffix),
+		fmt.Sprintf(OJLeaderboardKey, suffix),
+		fmt.Sprintf(OJLbSolvedKey, suffix, submitter),
+		fmt.Sprintf(OJLbSubmitsKey, suffix),
+		fmt.Sprintf(OJLbRebuildKey, suffix),
+		fmt.Sprintf(OJLbPendingKey, suffix),
+	}
+	forceArg := "0"
+	if force {
+		forceArg = "1"
+	}
+	return leaderboardVerdictScript.Run(ctx, config.RedisClient, keys, submitter, solvedProblem,
+		int64(leaderboardScoreBase), int64(ttl/time.Second), submissionID, forceArg).Err()
+}
+
+// recordLeaderboardVerdict 判题结束后更新各周期排行榜
+func recordLeaderboardVerdict(submission *entity.Submission) error {
+	if submission.Submitter == "" {
+		return nil
+	}
+	solvedProblem := ""
+	if submission.Status == "ACCEPTED" {
+		solvedProblem = strconv.FormatUint(uint64(submission.ProblemID), 10)
+	}
+
+	for _, period := range []string{LeaderboardAll, LeaderboardWeek, LeaderboardMonth} {
+		suffix, _, ttl := leaderboardWindow(period, submission.SubmitTime)
+		// 排行榜尚未构建时跳过，首次查询时会从数据库重建
+		if err := runLeaderboardVerdict(suffix, ttl, submission.ID, submission.Submitter, solvedProblem, false); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// leaderboardFinishRebuildScript 暂存的判题结果已全部补记时去掉重建标记并返回1，否则返回0
+// KEYS: 重建标记、暂存的判题结果
+var leaderboardFinishRebuildScript = redis.NewScript(`
+if redis.call('HLEN', KEYS[2]) > 0 then
+	return 0
+end
+redis.call('DEL', KEYS[1])
+return 1
+`)
+
+// RebuildLeaderboard 从submissions表重建指定周期的排行榜
+func RebuildLeaderboard(period string) error {
+	suffix, start, ttl := leaderboardWindow(period, time.Now())
+	boardKey := fmt.Sprintf(OJLeaderboardKey, suffix)
+	submitsKey := fmt.Sprintf(OJLbSubmitsKey, suffix)
+	builtKey := fmt.Sprintf(OJLbBuiltKey, suffix)
+	rebuildKey := fmt.Sprintf(OJLbRebuildKey, suffix)
+	pendingKey := fmt.Sprintf(OJLbPendingKey, suffix)
+
+	// 先标记为正在重建并去掉构建标记，之后的判题结果暂存起来，快照写入后再补记
+	pipe := config.RedisClient.TxPipeline()
+	pipe.Del(ctx, builtKey, pendingKey)
+	pipe.Set(ctx, rebuildKey, time.Now().Unix(), leaderboardRebuildTTL)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return err
+	}
+
+	// 在同一个只读事务中读取快照，补记时据此判断暂存的判题结果是否已包含在快照中
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		base := tx.Model(&entity.Submission{}).
+			Where("submitter <> '' AND status IN ?", verdictStatuses)
+		if !start.IsZero() {
+			base = base.Where("submit_time >= ?", start)
+		}
+		base = base.Session(&gorm.Session{})
+
+		// 每个用户的提交次数
+		var counts []struct {
+			Submitter string
+			Total     int64
+		}
+		if err := base.Select("submitter, COUNT(*) AS total").
+			Group("submitter").Scan(&counts).Error; err != nil {
+			return err
+		}
+
+		// 每个用户通过的题目
+		var solvedRows []struct {
+			Submitter string
+			ProblemID uint
+		}
+		if err := base.Distinct("submitter", "problem_id").
+			Where("status = ?", "ACCEPTED").Scan(&solvedRows).Error; err != nil {
+			return err
+		}
+		solvedMap := make(map[string][]interface{})
+		for _, row := range solvedRows {
+			solvedMap[row.Submitter] = append(solvedMap[row.Submitter], row.ProblemID)
+		}
+
+		// 清除旧数据
+		iter := config.RedisClient.Scan(ctx, 0, fmt.Sprintf(OJLbSolvedKey, suffix, "*"), 0).Iterator()
+		var staleKeys []string
+		for iter.Next(ctx) {
+			staleKeys = append(staleKeys, iter.Val())
+		}
+		if err := iter.Err(); err != nil {
+			return err
+		}
+
+		pipe := config.RedisClient.TxPipeline()
+		pipe.Del(ctx, append(staleKeys, boardKey, submitsKey, builtKey)...)
+		for _, c := range counts {
+			solved := solvedMap[c.Submitter]
+			if len(solved) > 0 {
+				solvedKey := fmt.Sprintf(OJLbSolvedKey, suffix, c.Submitter)
+				pipe.SAdd(ctx, solvedKey, solved...)
+				if ttl > 0 {
+					pipe.Expire(ctx, solvedKey, ttl)
+				}
+			}
+			pipe.HSet(ctx, submitsKey, c.Submitter, c.Total)
+			pipe.ZAdd(ctx, boardKey, &redis.Z{Score: leaderboardScore(int64(len(solved)), c.Total), Member: c.Submitter})
+		}
+		// 标记已构建，补记暂存的判题结果后由判题结果增量维护
+		pipe.Set(ctx, builtKey, time.Now().Unix(), ttl)
+		if ttl > 0 {
+			pipe.Expire(ctx, boardKey, ttl)
+			pipe.Expire(ctx, submitsKey, ttl)
+		}
+		if _, err := pipe.Exec(ctx); err != nil {
+			return err
+		}
+		return replayLeaderboardVerdicts(base, suffix, ttl)
+	}, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		// 重建失败时清除构建标记，下次查询时重新构建
+		if delErr := config.RedisClient.Del(ctx, builtKey, rebuildKey, pendingKey).Err(); delErr != nil {
+			log.Printf("清除排行榜构建标记失败: %v", delErr)
+		}
+	}
+	return err
+}
+
+// replayLeaderboardVerdicts 补记重建期间暂存的判题结果：快照中已包含的提交跳过，其余直接计入，全部处理后去掉重建标记
+func replayLeaderboardVerdicts(snapshot *gorm.DB, suffix string, ttl time.Duration) error {
+	rebuildKey := fmt.Sprintf(OJLbRebuildKey, suffix)
+	pendingKey := fmt.Sprintf(OJLbPendingKey, suffix)
+	for {
+		pending, err := config.RedisClient.HGetAll(ctx, pendingKey).Result()
+		if err != nil {
+			return err
+		}
+		if len(pending) == 0 {
+			done, err := leaderboardFinishRebuildScript.Run(ctx, config.RedisClient, []string{rebuildKey, pendingKey}).Int()
+			if err != nil || done == 1 {
+				return err
+			}
+			continue
+		}
+
+		ids := make([]uint, 0, len(pending))
+		for field := range pending {
+			if id, err := strconv.ParseUint(field, 10, 32); err == nil {
+				ids = append(ids, uint(id))
+			}
+		}
+		var counted []uint
+		if err := snapshot.Where("id IN ?", ids).Pluck("id", &counted).Error; err != nil {
+			return err
+		}
+		inSnapshot := make(map[uint]bool, len(counted))
+		for _, id := range counted {
+			inSnapshot[id] = true
+		}
+
+		for field, value := range pending {
+			id, err := strconv.ParseUint(field, 10, 32)
+			if err == nil && !inSnapshot[uint(id)] {
+				solvedProblem, submitter, _ := strings.Cut(value, "|")
+				if err := runLeaderboardVerdict(suffix, ttl, uint(id), submitter, solvedProblem, true); err != nil {
+					return err
+				}
+			}
+			if err := config.RedisClient.HDel(ctx, pendingKey, field).Err(); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// invalidateLeaderboards 提交记录被批量删除或恢复后去掉各周期排行榜的构建标记，下次查询时从数据库重建
+func invalidateLeaderboards() error {
+	redisService := &RedisService{}
+	return redisService.DeleteKeysByPattern(fmt.Sprintf(OJLbBuiltKey, "*"))
+}
+
+// GetLeaderboard 分页获取排行榜，并返回当前用户的排名
+func GetLeaderboard(period string, page, pageSize int, submitter string) (*dto.LeaderboardResponse, error) {
+	suffix, _, _ := leaderboardWindow(period, time.Now())
+	boardKey := fmt.Sprintf(OJLeaderboardKey, suffix)
+
+	exists, err := config.RedisClient.Exists(ctx, fmt.Sprintf(OJLbBuiltKey, suffix)).Result()
+	if err != nil {
+		return nil, err
+	}
+	if exists == 0 {
+		// 合并并发的重建请求
+		if _, err := cacheFlight.do("leaderboard:rebuild:"+suffix, func() (string, error) {
+			return "", RebuildLeaderboard(period)
+		}); err != nil {
+			return nil, err
+		}
+	}
+
+	total, err := config.RedisClient.ZCard(ctx, boardKey).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	start := int64((page - 1) * pageSize)
+	members, err := config.RedisClient.ZRevRangeWithScores(ctx, boardKey, start, start+int64(pageSize)-1).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &dto.LeaderboardResponse{
+		Period:   period,
+		Total:    total,
+		Page:     page,
+		PageSize: pageSize,
+		List:     make([]dto.LeaderboardEntry, 0, len(members)),
+	}
+	for _, m := range members {
+		name, _ := m.Member.(string)
+		entry, err := buildLeaderboardEntry(suffix, name, m.Score, submitter)
+		if err != nil {
+			return nil, err
+		}
+		resp.List = append(resp.List, *entry)
+	}
+
+	if submitter != "" {
+		score, err := config.RedisClient.ZScore(ctx, boardKey, submitter).Result()
+		if err != nil && err != redis.Nil {
+			return nil, err
+		}
+		if err == nil {
+			resp.Me, err = buildLeaderboardEntry(suffix, submitter, score, submitter)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	return resp, nil
+}
+
+// buildLeaderboardEntry 构建排行榜条目，分数相同的用户并列排名；me为当前用户，只用于标记是否本人
+func buildLeaderboardEntry(suffix, submitter string, score float64, me string) (*dto.LeaderboardEntry, error) {
+	boardKey := fmt.Sprintf(OJLeaderboardKey, suffix)
+	higher, err := config.RedisClient.ZCount(ctx, boardKey, "("+strconv.FormatFloat(score, 'f', -1, 64), "+inf").Result()
+	if err != nil {
+		return nil, err
+	}
+	solved, err := config.RedisClient.SCard(ctx, fmt.Sprintf(OJLbSolvedKey, suffix, submitter)).Result()
+	if err != nil {
+		return nil, err
+	}
+	submits, err := config.RedisClient.HGet(ctx, fmt.Sprintf(OJLbSubmitsKey, suffix), submitter).Int64()
+	if err != nil && err != redis.Nil {
+		return nil, err
+	}
+	return &dto.LeaderboardEntry{
+		Rank:        higher + 1,
+		DisplayId:   leaderboardDisplayID(submitter),
+		IsMe:        me != "" && submitter == me,
+		Solved:      solved,
+		Submissions: submits,
+	}, nil
+}
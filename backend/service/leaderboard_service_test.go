@@ -0,0 +1,29 @@
+package service
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestLeaderboardDisplayID(t *testing.T) {
+	format := regexp.MustCompile(`^u-[0-9a-f]{12}$`)
+	submitters := []string{"192.0.2.1", "192.0.2.2", "alice", "2001:db8::1", ""}
+	seen := make(map[string]string)
+	for _, submitter := range submitters {
+		id := leaderboardDisplayID(submitter)
+		if !format.MatchString(id) {
+			t.Errorf("leaderboardDisplayID(%q) = %q, 格式错误", submitter, id)
+		}
+		if submitter != "" && strings.Contains(id, submitter) {
+			t.Errorf("leaderboardDisplayID(%q) = %q 暴露了提交者标识", submitter, id)
+		}
+		if again := leaderboardDisplayID(submitter); again != id {
+			t.Errorf("leaderboardDisplayID(%q) 不稳定: %q != %q", submitter, id, again)
+		}
+		if other, ok := seen[id]; ok {
+			t.Errorf("%q 与 %q 的匿名标识相同: %q", submitter, other, id)
+		}
+		seen[id] = submitter
+	}
+}
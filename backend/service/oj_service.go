@@ -5,24 +5,48 @@ import (
 	"backend/dto"
 	"backend/entity"
 	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io"
+	"log"
 	"net/http"
-	"os"
 	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
-// GetAllProblems 获取所有OJ问题
-func GetAllProblems() ([]dto.OJProblemResponse, error) {
+// GetAllProblems 获取所有OJ问题，附带提交者的做题状态，可按状态筛选
+func GetAllProblems(submitter, status string) ([]dto.OJProblemResponse, error) {
 	var problems []entity.OJProblem
+	query := config.DB
+	switch status {
+	case ProblemStatusSolved, ProblemStatusAttempted:
+		query = query.Where("id IN (?)", userProblemIdsByStatus(submitter, status))
+	case ProblemStatusUnsolved:
+		query = query.Where("id NOT IN (?)", userProblemIdsByStatus(submitter, ProblemStatusSolved))
+	case ProblemStatusUntouched:
+		query = query.Where("id NOT IN (?)", config.DB.Model(&entity.UserProblemStatus{}).
+			Select("problem_id").Where("submitter = ?", submitter))
+	}
 	// 预加载测试用例和提交记录
-	if err := config.DB.Preload("Testcases").Preload("Submissions").Find(&problems).Error; err != nil {
+	if err := query.Preload("Testcases").Preload("Submissions").Find(&problems).Error; err != nil {
+		return nil, err
+	}
+
+	statusMap, err := getUserProblemStatusMap(submitter)
+	if err != nil {
 		return nil, err
 	}
 
 	var responses []dto.OJProblemResponse
 	for _, problem := range problems {
+		userStatus, ok := statusMap[problem.ID]
+		if !ok {
+			userStatus = ProblemStatusUntouched
+		}
 		responses = append(responses, dto.OJProblemResponse{
 			ID:          problem.ID,
 			Title:       problem.Title,
@@ -30,6 +54,8 @@ func GetAllProblems() ([]dto.OJProblemResponse, error) {
 			Difficulty:  problem.Difficulty,
 			TimeLimit:   problem.TimeLimit,
 			MemoryLimit: problem.MemoryLimit,
+			UserStatus:  userStatus,
+			Version:     problem.Version,
 			CreatedAt:   problem.CreatedAt.Format("2006-01-02 15:04:05"),
 			UpdatedAt:   problem.UpdatedAt.Format("2006-01-02 15:04:05"),
 		})
@@ -38,6 +64,12 @@ func GetAllProblems() ([]dto.OJProblemResponse, error) {
 	return responses, nil
 }
 
+// userProblemIdsByStatus 用户处于指定状态的题目ID子查询
+func userProblemIdsByStatus(submitter, status string) *gorm.DB {
+	return config.DB.Model(&entity.UserProblemStatus{}).
+		Select("problem_id").Where("submitter = ? AND status = ?", submitter, status)
+}
+
 // GetProblemById 根据ID获取OJ问题详情
 func GetProblemById(problemId uint) (*dto.OJProblemResponse, error) {
 	var problem entity.OJProblem
@@ -52,6 +84,7 @@ func GetProblemById(problemId uint) (*dto.OJProblemResponse, error) {
 		Difficulty:  problem.Difficulty,
 		TimeLimit:   problem.TimeLimit,
 		MemoryLimit: problem.MemoryLimit,
+		Version:     problem.Version,
 		CreatedAt:   problem.CreatedAt.Format("2006-01-02 15:04:05"),
 		UpdatedAt:   problem.UpdatedAt.Format("2006-01-02 15:04:05"),
 	}, nil
@@ -78,6 +111,7 @@ func CreateProblem(req dto.OJProblemCreateRequest) (*dto.OJProblemResponse, erro
 	if err := config.DB.Create(&problem).Error; err != nil {
 		return nil, err
 	}
+	invalidateFeedCache()
 
 	return &dto.OJProblemResponse{
 		ID:          problem.ID,
@@ -86,35 +120,80 @@ func CreateProblem(req dto.OJProblemCreateRequest) (*dto.OJProblemResponse, erro
 		Difficulty:  problem.Difficulty,
 		TimeLimit:   problem.TimeLimit,
 		MemoryLimit: problem.MemoryLimit,
+		Version:     problem.Version,
 		CreatedAt:   problem.CreatedAt.Format("2006-01-02 15:04:05"),
 		UpdatedAt:   problem.UpdatedAt.Format("2006-01-02 15:04:05"),	}, nil
 }
 
-// UpdateProblem 更新OJ问题
-func UpdateProblem(problemId uint, req dto.OJProblemUpdateRequest) (*dto.OJProblemResponse, error) {
-	var problem entity.OJProblem
-	if err := config.DB.First(&problem, problemId).Error; err != nil {
+// UpdateProblem 更新OJ问题（完整替换），ifMatch为期望的版本号，0表示不校验
+func UpdateProblem(problemId uint, req dto.OJProblemUpdateRequest, ifMatch uint) (*dto.OJProblemResponse, error) {
+	return saveProblemChanges(problemId, ifMatch, func(problem *entity.OJProblem) {
+		problem.Title = req.Title
+		problem.Description = req.Description
+		problem.Difficulty = req.Difficulty
+		problem.TimeLimit = req.TimeLimit
+		problem.MemoryLimit = req.MemoryLimit
+	})
+}
+
+// PatchProblem 按JSON Merge Patch修改OJ问题，只修改请求中出现的字段
+func PatchProblem(problemId uint, req dto.OJProblemPatchRequest, ifMatch uint) (*dto.OJProblemResponse, error) {
+	if err := checkPatchRequired(req.Title, "title"); err != nil {
 		return nil, err
 	}
-
-	// 更新字段
-	problem.Title = req.Title
-	problem.Description = req.Description
-	problem.Difficulty = req.Difficulty
-	problem.TimeLimit = req.TimeLimit
-	problem.MemoryLimit = req.MemoryLimit
-
-	// 设置默认值
-	if problem.TimeLimit == 0 {
-		problem.TimeLimit = 1000
+	if err := checkPatchRequired(req.Description, "description"); err != nil {
+		return nil, err
 	}
-	if problem.MemoryLimit == 0 {
-		problem.MemoryLimit = 256
+	if err := checkPatchRequired(req.Difficulty, "difficulty"); err != nil {
+		return nil, err
 	}
 
-	if err := config.DB.Save(&problem).Error; err != nil {
+	return saveProblemChanges(problemId, ifMatch, func(problem *entity.OJProblem) {
+		if req.Title.Set {
+			problem.Title = req.Title.Value
+		}
+		if req.Description.Set {
+			problem.Description = req.Description.Value
+		}
+		if req.Difficulty.Set {
+			problem.Difficulty = req.Difficulty.Value
+		}
+		// null或0恢复默认值
+		if req.TimeLimit.Set {
+			problem.TimeLimit = req.TimeLimit.Value
+		}
+		if req.MemoryLimit.Set {
+			problem.MemoryLimit = req.MemoryLimit.Value
+		}
+	})
+}
+
+// saveProblemChanges 在事务中校验版本号、修改并保存OJ问题，版本号加1
+func saveProblemChanges(problemId, ifMatch uint, apply func(problem *entity.OJProblem)) (*dto.OJProblemResponse, error) {
+	var problem entity.OJProblem
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&problem, problemId).Error; err != nil {
+			return err
+		}
+		if err := checkVersion(ifMatch, problem.Version); err != nil {
+			return err
+		}
+
+		apply(&problem)
+		// 设置默认值
+		if problem.TimeLimit == 0 {
+			problem.TimeLimit = 1000
+		}
+		if problem.MemoryLimit == 0 {
+			problem.MemoryLimit = 256
+		}
+		problem.Version++
+		return tx.Save(&problem).Error
+	})
+	if err != nil {
 		return nil, err
 	}
+	invalidateFeedCache()
 
 	return &dto.OJProblemResponse{
 		ID:          problem.ID,
@@ -123,13 +202,14 @@ func UpdateProblem(problemId uint, req dto.OJProblemUpdateRequest) (*dto.OJProbl
 		Difficulty:  problem.Difficulty,
 		TimeLimit:   problem.TimeLimit,
 		MemoryLimit: problem.MemoryLimit,
+		Version:     problem.Version,
 		CreatedAt:   problem.CreatedAt.Format("2006-01-02 15:04:05"),
 		UpdatedAt:   problem.UpdatedAt.Format("2006-01-02 15:04:05"),
 	}, nil
 }
 
-// DeleteProblem 删除OJ问题 (使用级联删除)
-func DeleteProblem(problemId uint) error {
+// DeleteProblem 删除OJ问题（连同测试用例和提交记录移入回收站），ifMatch为期望的版本号，0表示不校验
+func DeleteProblem(problemId, ifMatch uint) error {
 	tx := config.DB.Begin()
 	defer func() {
 		if r := recover(); r != nil {
@@ -138,18 +218,34 @@ func DeleteProblem(problemId uint) error {
 	}()
 
 	var problem entity.OJProblem
-	if err := tx.First(&problem, problemId).Error; err != nil {
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&problem, problemId).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err := checkVersion(ifMatch, problem.Version); err != nil {
 		tx.Rollback()
 		return err
 	}
 
-	// 利用GORM的级联删除，删除相关的测试用例和提交记录
-	if err := tx.Select("Testcases", "Submissions").Delete(&problem).Error; err != nil {
+	// 软删除题目及其测试用例和提交记录（使用同一删除时间，从回收站恢复时一并恢复）
+	deletedAt := trashDeleteTime()
+	for _, model := range []interface{}{&entity.OJTestcase{}, &entity.Submission{}} {
+		if err := softDeleteTx(tx, deletedAt, model, "problem_id = ?", problemId); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	if err := softDeleteTx(tx, deletedAt, &problem, "id = ?", problemId); err != nil {
 		tx.Rollback()
 		return err
 	}
 
-	return tx.Commit().Error
+	if err := tx.Commit().Error; err != nil {
+		return err
+	}
+	invalidateFeedCache()
+	invalidateProblemStats(problemId)
+	return nil
 }
 
 // CreateTestcase 为问题创建测试用例
@@ -184,7 +280,7 @@ func GetSubmissionStatus(token string) (*dto.SubmissionResponse, error) {
 	if err := config.DB.Where("judge_token = ?", token).First(&submission).Error; err != nil {
 		return nil, err
 	}
-	return &dto.SubmissionResponse{
+	resp := &dto.SubmissionResponse{
 		ID:          submission.ID,
 		ProblemId:   submission.ProblemID,
 		Code:        submission.Code,
@@ -194,7 +290,13 @@ func GetSubmissionStatus(token string) (*dto.SubmissionResponse, error) {
 		ExecuteTime: submission.ExecuteTime,
 		MemoryUsage: submission.MemoryUsage,
 		SubmitTime:  submission.SubmitTime.Format("2006-01-02 15:04:05"),
-		JudgeToken:  submission.JudgeToken}, nil
+		JudgeToken:  submission.JudgeToken}
+
+	// 通过的提交附带执行时间与内存的百分位
+	if err := fillPerformancePercentiles(resp); err != nil {
+		log.Printf("计算提交百分位失败: %v", err)
+	}
+	return resp, nil
 }
 
 // GetTestcases 获取指定问题的测试用例
@@ -226,6 +328,18 @@ func GetTestcases(problemId uint) ([]dto.OJTestcaseResponse, error) {
 
 // SubmitCode 提交代码进行评测
 func SubmitCode(req dto.SubmissionCreateRequest) (*dto.SubmissionResponse, error) {
+	// 熔断期间直接拒绝，避免产生无法评测的提交记录
+	if !AllowJudgeDispatch() {
+		return nil, ErrJudgeUnavailable
+	}
+	// 半开状态下取得了探测名额却没有调用Judge0时归还名额
+	probed := false
+	defer func() {
+		if !probed {
+			releaseJudgeProbe()
+		}
+	}()
+
 	// 验证问题是否存在
 	var problem entity.OJProblem
 	if err := config.DB.First(&problem, req.ProblemId).Error; err != nil {
@@ -245,14 +359,68 @@ func SubmitCode(req dto.SubmissionCreateRequest) (*dto.SubmissionResponse, error
 		ProblemID:  req.ProblemId,
 		Code:       req.Code,
 		Language:   req.Language,
-		Status:     "等待中",
+		Status:     SubmissionStatusWaiting,
 		SubmitTime: time.Now(),
+		Submitter:  req.Submitter,
 	}
 
 	if err := config.DB.Create(&submission).Error; err != nil {
 		return nil, err
-	} // 调用Judge0 API获取token
-	testcase := testcases[0]
+	}
+
+	// 调用Judge0 API获取token
+	judge0URL := getJudge0URL()
+	probed = true
+	token, err := dispatchToJudge0(judge0URL, &submission, testcases[0])
+	if err != nil {
+		recordJudgeFailure(err)
+		// 标记派发失败，避免留下永远"等待中"的记录
+		config.DB.Model(&submission).Update("status", SubmissionStatusDispatchFailed)
+		return nil, err
+	}
+	recordJudgeSuccess()
+
+	// 更新submission的token
+	submission.JudgeToken = token
+	submission.Status = "IN_QUEUE"
+	if err := config.DB.Save(&submission).Error; err != nil {
+		return nil, err
+	}
+
+	// 异步轮询结果
+	go pollJudgeResult(&submission, judge0URL)
+	return &dto.SubmissionResponse{
+		ID:          submission.ID,
+		ProblemId:   submission.ProblemID,
+		Code:        submission.Code,
+		Language:    submission.Language,
+		Status:      submission.Status,
+		IsCompleted: isJudgeCompleted(submission.Status),
+		ExecuteTime: submission.ExecuteTime,
+		MemoryUsage: submission.MemoryUsage,
+		SubmitTime:  submission.SubmitTime.Format("2006-01-02 15:04:05"),
+		JudgeToken:  submission.JudgeToken,
+	}, nil
+}
+
+// SubmissionFingerprint 计算提交内容指纹（提交者+题目+语言+代码）
+func SubmissionFingerprint(req dto.SubmissionCreateRequest) string {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s\x00%d\x00%s\x00%s", req.Submitter, req.ProblemId, req.Language, req.Code)))
+	return hex.EncodeToString(sum[:])
+}
+
+// IdempotencyTTL Idempotency-Key的保留时长
+func IdempotencyTTL() time.Duration {
+	return time.Duration(getEnvInt("OJ_IDEMPOTENCY_TTL_SECONDS", 24*60*60)) * time.Second
+}
+
+// SubmitDedupWindow 相同提交去重的时间窗口，为0表示不去重
+func SubmitDedupWindow() time.Duration {
+	return time.Duration(getEnvInt("OJ_SUBMIT_DEDUP_SECONDS", 0)) * time.Second
+}
+
+// dispatchToJudge0 将提交派发到Judge0，返回评测token
+func dispatchToJudge0(judge0URL string, submission *entity.Submission, testcase entity.OJTestcase) (string, error) {
 	languageId := getLanguageId(submission.Language)
 	judge0Req := dto.Judge0SubmissionRequest{
 		SourceCode:     submission.Code,
@@ -264,66 +432,41 @@ func SubmitCode(req dto.SubmissionCreateRequest) (*dto.SubmissionResponse, error
 	}
 	jsonData, err := json.Marshal(judge0Req)
 	if err != nil {
-		return nil, fmt.Errorf("JSON编码失败: %v", err)
-	}
-	judge0URL := os.Getenv("JUDGE0_URL")
-	if judge0URL == "" {
-		// 使用配置的默认Judge0服务地址
-		judge0URL = "http://47.92.90.228:2358/submissions"
+		return "", fmt.Errorf("JSON编码失败: %v", err)
 	}
 	// 添加调试日志
 	fmt.Printf("尝试连接Judge0服务: %s\n", judge0URL)
 	fmt.Printf("请求数据: %s\n", string(jsonData))
 
-	resp, err := http.Post(judge0URL, "application/json", bytes.NewBuffer(jsonData))
+	resp, err := judgeHTTPClient.Post(judge0URL, "application/json", bytes.NewBuffer(jsonData))
 	if err != nil {
-		return nil, fmt.Errorf("Judge0服务调用失败: %v", err)
+		return "", fmt.Errorf("Judge0服务调用失败: %v", err)
 	}
 	defer resp.Body.Close()
 
 	// 检查HTTP状态码
 	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
 		bodyBytes, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("Judge0服务返回错误状态码: %d, 响应: %s", resp.StatusCode, string(bodyBytes))
+		return "", fmt.Errorf("Judge0服务返回错误状态码: %d, 响应: %s", resp.StatusCode, string(bodyBytes))
 	}
 
 	// 读取响应体用于调试
 	bodyBytes, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return nil, fmt.Errorf("读取Judge0响应失败: %v", err)
+		return "", fmt.Errorf("读取Judge0响应失败: %v", err)
 	}
 
 	fmt.Printf("Judge0响应: %s\n", string(bodyBytes))
 
 	var judge0Resp dto.Judge0SubmissionResponse
 	if err := json.Unmarshal(bodyBytes, &judge0Resp); err != nil {
-		return nil, fmt.Errorf("Judge0响应解析失败: %v, 响应内容: %s", err, string(bodyBytes))
+		return "", fmt.Errorf("Judge0响应解析失败: %v, 响应内容: %s", err, string(bodyBytes))
 	}
 
 	if judge0Resp.Token == "" {
-		return nil, fmt.Errorf("Judge0未返回token")
-	}
-	// 更新submission的token
-	submission.JudgeToken = judge0Resp.Token
-	submission.Status = "IN_QUEUE"
-	if err := config.DB.Save(&submission).Error; err != nil {
-		return nil, err
+		return "", fmt.Errorf("Judge0未返回token")
 	}
-
-	// 异步轮询结果
-	go pollJudgeResult(&submission, judge0URL)
-	return &dto.SubmissionResponse{
-		ID:          submission.ID,
-		ProblemId:   submission.ProblemID,
-		Code:        submission.Code,
-		Language:    submission.Language,
-		Status:      submission.Status,
-		IsCompleted: isJudgeCompleted(submission.Status),
-		ExecuteTime: submission.ExecuteTime,
-		MemoryUsage: submission.MemoryUsage,
-		SubmitTime:  submission.SubmitTime.Format("2006-01-02 15:04:05"),
-		JudgeToken:  submission.JudgeToken,
-	}, nil
+	return judge0Resp.Token, nil
 }
 
 // pollJudgeResult 轮询Judge0结果
@@ -331,7 +474,7 @@ func pollJudgeResult(submission *entity.Submission, judge0URL string) {
 	for i := 0; i < 30; i++ { // 最多轮询30次
 		time.Sleep(1 * time.Second)
 
-		resp, err := http.Get(fmt.Sprintf("%s/%s?base64_encoded=true", judge0URL, submission.JudgeToken))
+		resp, err := judgeHTTPClient.Get(fmt.Sprintf("%s/%s?base64_encoded=true", judge0URL, submission.JudgeToken))
 		if err != nil {
 			continue
 		}
@@ -346,11 +489,11 @@ func pollJudgeResult(submission *entity.Submission, judge0URL string) {
 				submission.ExecuteTime = int(parseFloat(statusResp.Time) * 1000) // 转换为毫秒
 			}
 			submission.MemoryUsage = statusResp.Memory
-			config.DB.Save(submission)
+			finalizeSubmission(submission)
 			return
 		} else if statusResp.Status.ID > 3 { // 其他状态表示失败
 			submission.Status = "WRONG_ANSWER"
-			config.DB.Save(submission)
+			finalizeSubmission(submission)
 			return
 		}
 	}
@@ -359,6 +502,23 @@ func pollJudgeResult(submission *entity.Submission, judge0URL string) {
 	config.DB.Save(submission)
 }
 
+// finalizeSubmission 保存最终判题结果并更新相关统计
+func finalizeSubmission(submission *entity.Submission) {
+	if err := config.DB.Save(submission).Error; err != nil {
+		log.Printf("保存判题结果失败: %v", err)
+		return
+	}
+	if err := recordUserProblemVerdict(submission); err != nil {
+		log.Printf("更新用户做题状态失败: %v", err)
+	}
+	if err := recordLeaderboardVerdict(submission); err != nil {
+		log.Printf("更新排行榜失败: %v", err)
+	}
+	if err := recordAcceptedPerformance(submission); err != nil {
+		log.Printf("更新执行时间/内存分布失败: %v", err)
+	}
+}
+
 // getLanguageId 获取Judge0语言ID
 func getLanguageId(language string) int {
 	switch language {
@@ -387,6 +547,7 @@ func isJudgeCompleted(status string) bool {
 	completedStatuses := []string{
 		"ACCEPTED", "WRONG_ANSWER", "TIME_LIMIT_EXCEEDED",
 		"COMPILATION_ERROR", "RUNTIME_ERROR", "MEMORY_LIMIT_EXCEEDED",
+		SubmissionStatusDispatchFailed,
 	}
 	for _, s := range completedStatuses {
 		if status == s {
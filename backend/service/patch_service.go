@@ -0,0 +1,19 @@
+package service
+
+import (
+	"backend/dto"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrPatchFieldRequired 合并修改（PATCH）时把必填字段设置为null或空值
+var ErrPatchFieldRequired = errors.New("不能为null或空")
+
+// checkPatchRequired 校验必填字符串字段：可以不提供，但提供时不能为null或空
+func checkPatchRequired(field dto.PatchField[string], name string) error {
+	if field.Set && (field.Null || strings.TrimSpace(field.Value) == "") {
+		return fmt.Errorf("%s%w", name, ErrPatchFieldRequired)
+	}
+	return nil
+}
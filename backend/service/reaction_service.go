@@ -0,0 +1,186 @@
+package service
+
+import (
+	"backend/config"
+	"backend/dto"
+	"backend/entity"
+	"backend/utils"
+	"errors"
+	"fmt"
+	"log"
+)
+
+// 文章回应类型
+const (
+	ReactionLike     = "like"     // 点赞
+	ReactionLove     = "love"     // 喜欢
+	ReactionClap     = "clap"     // 鼓掌
+	ReactionLaugh    = "laugh"    // 有趣
+	ReactionConfused = "confused" // 疑惑
+)
+
+// reactionTypes 支持的回应类型（按展示顺序）
+var reactionTypes = []string{ReactionLike, ReactionLove, ReactionClap, ReactionLaugh, ReactionConfused}
+
+// ErrInvalidReaction 不支持的回应类型
+var ErrInvalidReaction = errors.New("无效的回应类型，可选值：like/love/clap/laugh/confused")
+
+// isValidReaction 判断回应类型是否合法
+func isValidReaction(reaction string) bool {
+	for _, r := range reactionTypes {
+		if r == reaction {
+			return true
+		}
+	}
+	return false
+}
+
+// ReactToArticle 用户对文章做出回应（每位用户每种回应只计一次）
+func ReactToArticle(articleID uint, reaction, user string) (*dto.ArticleReactionsResponse, error) {
+	if !isValidReaction(reaction) {
+		return nil, ErrInvalidReaction
+	}
+	article, err := findPublicArticleForReaction(articleID)
+	if err != nil {
+		return nil, err
+	}
+
+	redisService := &RedisService{}
+	if err := seedReactionCounts(redisService, article); err != nil {
+		return nil, err
+	}
+	if _, err := redisService.AddArticleReaction(articleID, reaction, user); err != nil {
+		return nil, err
+	}
+	return GetArticleReactions(articleID, user)
+}
+
+// UnreactToArticle 取消用户对文章的回应
+func UnreactToArticle(articleID uint, reaction, user string) (*dto.ArticleReactionsResponse, error) {
+	if !isValidReaction(reaction) {
+		return nil, ErrInvalidReaction
+	}
+	article, err := findPublicArticleForReaction(articleID)
+	if err != nil {
+		return nil, err
+	}
+
+	redisService := &RedisService{}
+	if err := seedReactionCounts(redisService, article); err != nil {
+		return nil, err
+	}
+	if _, err := redisService.RemoveArticleReaction(articleID, reaction, user); err != nil {
+		return nil, err
+	}
+	return GetArticleReactions(articleID, user)
+}
+
+// GetArticleReactions 获取文章各类回应计数及当前用户已做出的回应
+func GetArticleReactions(articleID uint, user string) (*dto.ArticleReactionsResponse, error) {
+	article, err := findPublicArticleForReaction(articleID)
+	if err != nil {
+		return nil, err
+	}
+
+	redisService := &RedisService{}
+	counts, err := redisService.GetArticleReactions(articleID)
+	if err != nil {
+		return nil, err
+	}
+	if counts == nil {
+		counts = parseReactionCounts(article.Reactions)
+	}
+	mine, err := redisService.GetUserArticleReactions(articleID, user, reactionTypes)
+	if err != nil {
+		return nil, err
+	}
+	// Redis中的阅读量缺失（如重启后）时使用数据库中同步的值
+	views := article.Views
+	if redisViews, err := redisService.GetArticleViews(articleID); err == nil && redisViews > views {
+		views = redisViews
+	}
+
+	return &dto.ArticleReactionsResponse{
+		ArticleId: articleID,
+		Views:     views,
+		Likes:     counts[ReactionLike],
+		Reactions: completeReactionCounts(counts),
+		Mine:      mine,
+	}, nil
+}
+
+// findPublicArticleForReaction 只有已发布的文章可以被回应
+func findPublicArticleForReaction(articleID uint) (entity.Article, error) {
+	var article entity.Article
+	if err := config.DB.Select("id", "status", "views", "reactions").First(&article, articleID).Error; err != nil {
+		return article, err
+	}
+	if !isPublicArticle(article) {
+		return article, ErrArticleNotPublic
+	}
+	return article, nil
+}
+
+// seedReactionCounts Redis中的计数丢失（如重启后）时，先用数据库中的计数初始化，避免从0开始计数
+func seedReactionCounts(redisService *RedisService, article entity.Article) error {
+	counts := parseReactionCounts(article.Reactions)
+	if len(counts) == 0 {
+		return nil
+	}
+	return redisService.SeedArticleReactions(article.ID, counts)
+}
+
+// parseReactionCounts 解析数据库中保存的回应计数
+func parseReactionCounts(reactions string) map[string]int64 {
+	counts := make(map[string]int64)
+	if reactions != "" {
+		if err := utils.ParseJSONString(reactions, &counts); err != nil {
+			log.Printf("解析回应计数失败: %v", err)
+		}
+	}
+	return counts
+}
+
+// completeReactionCounts 补全所有回应类型（没有回应的计为0）
+func completeReactionCounts(counts map[string]int64) map[string]int64 {
+	result := make(map[string]int64, len(reactionTypes))
+	for _, r := range reactionTypes {
+		result[r] = counts[r]
+	}
+	return result
+}
+
+// syncReactionCountsToDatabase 同步Redis中的回应计数到数据库（与阅读量同步任务一同执行）
+func syncReactionCountsToDatabase() error {
+	iter := config.RedisClient.Scan(ctx, 0, "article:reactions:*", 0).Iterator()
+	redisService := &RedisService{}
+
+	for iter.Next(ctx) {
+		key := iter.Val()
+
+		var articleID uint
+		if _, err := fmt.Sscanf(key, ArticleReactionsKey, &articleID); err != nil {
+			log.Printf("解析文章ID失败: %s, %v", key, err)
+			continue
+		}
+
+		counts, err := redisService.GetArticleReactions(articleID)
+		if err != nil {
+			log.Printf("获取文章 %d 回应计数失败: %v", articleID, err)
+			continue
+		}
+
+		// 不修改文章的更新时间
+		if err := config.DB.Model(&entity.Article{}).Where("id = ?", articleID).UpdateColumns(map[string]interface{}{
+			"likes":     counts[ReactionLike],
+			"reactions": utils.ToJSONString(completeReactionCounts(counts)),
+		}).Error; err != nil {
+			log.Printf("同步文章 %d 回应计数到数据库失败: %v", articleID, err)
+		}
+	}
+
+	if err := iter.Err(); err != nil {
+		return fmt.Errorf("扫描Redis键失败: %v", err)
+	}
+	return nil
+}
@@ -3,7 +3,9 @@ package service
 import (
 	"backend/config"
 	"backend/entity"
+	"backend/utils"
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"strconv"
@@ -17,12 +19,45 @@ const (
 	ArticleViewsKey   = "article:views:%d"   // 文章总阅读量
 	ArticleIPKey      = "article:ip:%d_%s"   // IP访问记录
 	ArticleContentKey = "article:content:%d" // 文章内容缓存
+	ArticleRelatedKey = "article:related:%d" // 相关文章缓存（JSON）
 	OJSubmitRateKey   = "oj:submit:%s"       // OJ提交频率限制
+	OJIdempotencyKey  = "oj:idem:%s:%s"      // OJ提交幂等键（提交者:Idempotency-Key）
+	OJSubmitDedupKey  = "oj:dedup:%s"        // OJ重复提交去重（提交内容指纹）
+	OJLeaderboardKey  = "oj:lb:%s"           // OJ排行榜（有序集合，按周期）
+	OJLbSolvedKey     = "oj:lb:%s:solved:%s" // 排行榜周期内用户通过的题目集合
+	OJLbSubmitsKey    = "oj:lb:%s:submits"   // 排行榜周期内用户提交次数（哈希）
+	OJLbBuiltKey      = "oj:lb:%s:built"     // 排行榜已从数据库构建的标记
+	OJLbRebuildKey    = "oj:lb:%s:rebuild"   // 排行榜正在重建的标记
+	OJLbPendingKey    = "oj:lb:%s:pending"   // 重建期间暂存的判题结果（提交ID -> 题目ID|用户）
+	OJPerfTimeKey     = "oj:perf:%d:%s:time" // 通过提交的执行时间分布（题目:语言）
+	OJPerfMemoryKey   = "oj:perf:%d:%s:mem"  // 通过提交的内存使用分布（题目:语言）
+	FeedCacheKey      = "feed:%s"            // 订阅源与站点地图缓存（按类型与参数区分）
 	ViewCountSyncKey  = "sync:views"         // 阅读量同步标识
+
+	// 点赞等回应
+	ArticleReactorsKey  = "article:reaction:%d:%s" // 对文章做出某种回应的用户集合（文章:回应类型）
+	ArticleReactionsKey = "article:reactions:%d"   // 文章各类回应计数（哈希）
+
+	// 列表缓存（键中带版本号，数据变化时递增版本号使旧缓存失效）
+	CacheVersionKey = "cache:version:%s"    // 缓存版本号（articles/tags）
+	ArticleListKey  = "article:list:v%d:%s" // 文章列表分页缓存（版本:查询条件摘要）
+	TagListKey      = "tag:list:v%d"        // 标签列表缓存
+	ArchiveCountKey = "article:archive:v%d" // 文章归档统计缓存
+
+	// 文章每日访问统计（日期:文章ID），每晚汇总到数据库
+	ArticleStatsViewsKey     = "article:stats:%s:views:%d" // 当天阅读量
+	ArticleStatsVisitorsKey  = "article:stats:%s:uv:%d"    // 当天独立访客（HyperLogLog）
+	ArticleStatsReferrersKey = "article:stats:%s:ref:%d"   // 当天来源域名计数（哈希）
+
+	// 进程间通知：命令行等其他进程修改文章后，通知运行中的服务更新内存中的搜索索引
+	ArticleReindexChannel = "article:reindex" // 消息为逗号分隔的文章ID
 )
 
 var ctx = context.Background()
 
+// ErrIdempotencyKeyReused 同一幂等键被用于内容不同的请求
+var ErrIdempotencyKeyReused = errors.New("Idempotency-Key已被用于内容不同的提交")
+
 // RedisService Redis服务结构体
 type RedisService struct{}
 
@@ -106,6 +141,84 @@ func (rs *RedisService) CheckOJSubmitRateLimit(clientIP string) (bool, error) {
 	return true, err
 }
 
+// idempotencyPending 幂等键已占用但请求尚未完成时的占位值
+const idempotencyPending = "PENDING"
+
+// idempotencyReserveAttempts 占位恰好过期时重新占用的最大次数
+const idempotencyReserveAttempts = 3
+
+// idempotencyRecord 幂等键对应的提交结果
+type idempotencyRecord struct {
+	Fingerprint string `json:"fingerprint"` // 请求内容指纹，用于识别同一幂等键下的不同请求
+	Token       string `json:"token"`
+}
+
+// ReserveIdempotencyKey 占用幂等键。
+// 返回值 reserved 为 true 表示首次请求，应继续提交；
+// 否则 token 为之前请求的评测令牌（为空表示之前的请求仍在处理中）。
+func (rs *RedisService) ReserveIdempotencyKey(submitter, idemKey, fingerprint string) (token string, reserved bool, err error) {
+	key := fmt.Sprintf(OJIdempotencyKey, submitter, idemKey)
+
+	var val string
+	for attempt := 0; ; attempt++ {
+		// 占位时间需覆盖一次完整的Judge0派发
+		reserved, err = config.RedisClient.SetNX(ctx, key, idempotencyPending, 30*time.Second).Result()
+		if err != nil || reserved {
+			return "", reserved, err
+		}
+
+		val, err = config.RedisClient.Get(ctx, key).Result()
+		if err != redis.Nil {
+			break
+		}
+		// 占位恰好过期，重新尝试占用；多次仍未成功时按处理中返回
+		if attempt+1 >= idempotencyReserveAttempts {
+			return "", false, nil
+		}
+	}
+	if err != nil || val == idempotencyPending {
+		return "", false, err
+	}
+
+	var record idempotencyRecord
+	if err := utils.ParseJSONString(val, &record); err != nil {
+		return "", false, err
+	}
+	if record.Fingerprint != fingerprint {
+		return "", false, ErrIdempotencyKeyReused
+	}
+	return record.Token, false, nil
+}
+
+// SaveIdempotencyToken 保存幂等键对应的评测令牌
+func (rs *RedisService) SaveIdempotencyToken(submitter, idemKey, fingerprint, token string, ttl time.Duration) error {
+	key := fmt.Sprintf(OJIdempotencyKey, submitter, idemKey)
+	record := idempotencyRecord{Fingerprint: fingerprint, Token: token}
+	return config.RedisClient.Set(ctx, key, utils.ToJSONString(record), ttl).Err()
+}
+
+// ReleaseIdempotencyKey 提交失败时释放幂等键，允许客户端重试
+func (rs *RedisService) ReleaseIdempotencyKey(submitter, idemKey string) error {
+	key := fmt.Sprintf(OJIdempotencyKey, submitter, idemKey)
+	return config.RedisClient.Del(ctx, key).Err()
+}
+
+// GetDedupSubmissionToken 获取短时间窗口内相同提交的评测令牌
+func (rs *RedisService) GetDedupSubmissionToken(fingerprint string) (string, error) {
+	key := fmt.Sprintf(OJSubmitDedupKey, fingerprint)
+	token, err := config.RedisClient.Get(ctx, key).Result()
+	if err == redis.Nil {
+		return "", nil
+	}
+	return token, err
+}
+
+// SaveDedupSubmissionToken 记录提交内容指纹对应的评测令牌
+func (rs *RedisService) SaveDedupSubmissionToken(fingerprint, token string, window time.Duration) error {
+	key := fmt.Sprintf(OJSubmitDedupKey, fingerprint)
+	return config.RedisClient.Set(ctx, key, token, window).Err()
+}
+
 // CacheArticleContent 缓存文章内容（热门文章）
 func (rs *RedisService) CacheArticleContent(articleID uint, content string) error {
 	key := fmt.Sprintf(ArticleContentKey, articleID)
@@ -118,6 +231,240 @@ func (rs *RedisService) GetCachedArticleContent(articleID uint) (string, error)
 	return config.RedisClient.Get(ctx, key).Result()
 }
 
+// DeleteArticleContent 删除文章内容缓存
+func (rs *RedisService) DeleteArticleContent(articleIDs ...uint) error {
+	if len(articleIDs) == 0 {
+		return nil
+	}
+	keys := make([]string, 0, len(articleIDs))
+	for _, id := range articleIDs {
+		keys = append(keys, fmt.Sprintf(ArticleContentKey, id))
+	}
+	return config.RedisClient.Del(ctx, keys...).Err()
+}
+
+// DeleteArticleData 删除文章在Redis中的所有数据（阅读量、缓存、相关文章、回应），用于永久删除文章
+func (rs *RedisService) DeleteArticleData(articleID uint, reactions []string) error {
+	keys := []string{
+		fmt.Sprintf(ArticleViewsKey, articleID),
+		fmt.Sprintf(ArticleContentKey, articleID),
+		fmt.Sprintf(ArticleRelatedKey, articleID),
+		fmt.Sprintf(ArticleReactionsKey, articleID),
+	}
+	for _, reaction := range reactions {
+		keys = append(keys, fmt.Sprintf(ArticleReactorsKey, articleID, reaction))
+	}
+	return config.RedisClient.Del(ctx, keys...).Err()
+}
+
+// RecordArticleVisit 记录文章的每日访问：每次访问都计入当天独立访客，
+// counted为true（未被IP防刷过滤）时计入当天阅读量和来源域名
+func (rs *RedisService) RecordArticleVisit(articleID uint, day, clientIP, referrer string, counted bool) error {
+	ttl := articleStatsRedisDays * 24 * time.Hour
+	pipe := config.RedisClient.TxPipeline()
+
+	visitorsKey := fmt.Sprintf(ArticleStatsVisitorsKey, day, articleID)
+	pipe.PFAdd(ctx, visitorsKey, clientIP)
+	pipe.Expire(ctx, visitorsKey, ttl)
+	if counted {
+		viewsKey := fmt.Sprintf(ArticleStatsViewsKey, day, articleID)
+		pipe.Incr(ctx, viewsKey)
+		pipe.Expire(ctx, viewsKey, ttl)
+
+		referrersKey := fmt.Sprintf(ArticleStatsReferrersKey, day, articleID)
+		pipe.HIncrBy(ctx, referrersKey, referrer, 1)
+		pipe.Expire(ctx, referrersKey, ttl)
+	}
+
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// GetArticleDayStats 获取文章某天在Redis中的访问统计，Redis中没有该天的数据时返回nil
+func (rs *RedisService) GetArticleDayStats(articleID uint, day string) (*articleDayStats, error) {
+	pipe := config.RedisClient.Pipeline()
+	exists := pipe.Exists(ctx, fmt.Sprintf(ArticleStatsVisitorsKey, day, articleID))
+	visitors := pipe.PFCount(ctx, fmt.Sprintf(ArticleStatsVisitorsKey, day, articleID))
+	views := pipe.Get(ctx, fmt.Sprintf(ArticleStatsViewsKey, day, articleID))
+	referrers := pipe.HGetAll(ctx, fmt.Sprintf(ArticleStatsReferrersKey, day, articleID))
+	if _, err := pipe.Exec(ctx); err != nil && err != redis.Nil {
+		return nil, err
+	}
+	if exists.Val() == 0 {
+		return nil, nil
+	}
+
+	stats := &articleDayStats{
+		Visitors:  visitors.Val(),
+		Referrers: make(map[string]int64, len(referrers.Val())),
+	}
+	stats.Views, _ = strconv.ParseInt(views.Val(), 10, 64)
+	for domain, value := range referrers.Val() {
+		count, err := strconv.ParseInt(value, 10, 64)
+		if err == nil {
+			stats.Referrers[domain] = count
+		}
+	}
+	return stats, nil
+}
+
+// GetCacheVersion 获取缓存版本号，尚未写入过时为0
+func (rs *RedisService) GetCacheVersion(name string) (int64, error) {
+	version, err := config.RedisClient.Get(ctx, fmt.Sprintf(CacheVersionKey, name)).Int64()
+	if err == redis.Nil {
+		return 0, nil
+	}
+	return version, err
+}
+
+// BumpCacheVersion 递增缓存版本号，使该版本下的所有缓存失效
+func (rs *RedisService) BumpCacheVersion(name string) error {
+	return config.RedisClient.Incr(ctx, fmt.Sprintf(CacheVersionKey, name)).Err()
+}
+
+// GetCache 读取缓存
+func (rs *RedisService) GetCache(key string) (string, error) {
+	return config.RedisClient.Get(ctx, key).Result()
+}
+
+// SetCache 写入缓存
+func (rs *RedisService) SetCache(key, value string, ttl time.Duration) error {
+	return config.RedisClient.Set(ctx, key, value, ttl).Err()
+}
+
+// SaveRelatedArticles 缓存文章的相关文章列表
+func (rs *RedisService) SaveRelatedArticles(articleID uint, related interface{}) error {
+	key := fmt.Sprintf(ArticleRelatedKey, articleID)
+	return config.RedisClient.Set(ctx, key, utils.ToJSONString(related), 24*time.Hour).Err()
+}
+
+// GetRelatedArticles 读取缓存的相关文章列表
+func (rs *RedisService) GetRelatedArticles(articleID uint, related interface{}) error {
+	key := fmt.Sprintf(ArticleRelatedKey, articleID)
+	value, err := config.RedisClient.Get(ctx, key).Result()
+	if err != nil {
+		return err
+	}
+	return utils.ParseJSONString(value, related)
+}
+
+// reactionAddScript 用户首次回应时加入集合并计数，返回最新计数，重复回应返回-1
+var reactionAddScript = redis.NewScript(`
+if redis.call('SADD', KEYS[1], ARGV[1]) == 1 then
+	return redis.call('HINCRBY', KEYS[2], ARGV[2], 1)
+end
+return -1
+`)
+
+// reactionRemoveScript 用户取消回应时移出集合并计数，返回最新计数，未回应过返回-1
+var reactionRemoveScript = redis.NewScript(`
+if redis.call('SREM', KEYS[1], ARGV[1]) == 1 then
+	local count = redis.call('HINCRBY', KEYS[2], ARGV[2], -1)
+	if count < 0 then
+		redis.call('HSET', KEYS[2], ARGV[2], 0)
+		count = 0
+	end
+	return count
+end
+return -1
+`)
+
+// AddArticleReaction 记录用户对文章的回应（同一用户同一类型只计一次），返回是否为新回应
+func (rs *RedisService) AddArticleReaction(articleID uint, reaction, user string) (bool, error) {
+	keys := []string{fmt.Sprintf(ArticleReactorsKey, articleID, reaction), fmt.Sprintf(ArticleReactionsKey, articleID)}
+	count, err := reactionAddScript.Run(ctx, config.RedisClient, keys, user, reaction).Int64()
+	if err != nil {
+		return false, err
+	}
+	return count >= 0, nil
+}
+
+// RemoveArticleReaction 取消用户对文章的回应，返回是否确实取消了回应
+func (rs *RedisService) RemoveArticleReaction(articleID uint, reaction, user string) (bool, error) {
+	keys := []string{fmt.Sprintf(ArticleReactorsKey, articleID, reaction), fmt.Sprintf(ArticleReactionsKey, articleID)}
+	count, err := reactionRemoveScript.Run(ctx, config.RedisClient, keys, user, reaction).Int64()
+	if err != nil {
+		return false, err
+	}
+	return count >= 0, nil
+}
+
+// GetArticleReactions 获取文章各类回应计数，Redis中没有记录时返回nil
+func (rs *RedisService) GetArticleReactions(articleID uint) (map[string]int64, error) {
+	values, err := config.RedisClient.HGetAll(ctx, fmt.Sprintf(ArticleReactionsKey, articleID)).Result()
+	if err != nil || len(values) == 0 {
+		return nil, err
+	}
+	counts := make(map[string]int64, len(values))
+	for reaction, value := range values {
+		if count, err := strconv.ParseInt(value, 10, 64); err == nil {
+			counts[reaction] = count
+		}
+	}
+	return counts, nil
+}
+
+// SeedArticleReactions Redis中没有计数时用数据库中的计数初始化（已存在的字段不覆盖）
+func (rs *RedisService) SeedArticleReactions(articleID uint, counts map[string]int64) error {
+	key := fmt.Sprintf(ArticleReactionsKey, articleID)
+	pipe := config.RedisClient.TxPipeline()
+	for reaction, count := range counts {
+		pipe.HSetNX(ctx, key, reaction, count)
+	}
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// GetUserArticleReactions 获取用户对文章做出的回应类型
+func (rs *RedisService) GetUserArticleReactions(articleID uint, user string, reactions []string) ([]string, error) {
+	pipe := config.RedisClient.Pipeline()
+	cmds := make([]*redis.BoolCmd, len(reactions))
+	for i, reaction := range reactions {
+		cmds[i] = pipe.SIsMember(ctx, fmt.Sprintf(ArticleReactorsKey, articleID, reaction), user)
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, err
+	}
+	mine := []string{}
+	for i, cmd := range cmds {
+		if cmd.Val() {
+			mine = append(mine, reactions[i])
+		}
+	}
+	return mine, nil
+}
+
+// GetCachedFeed 获取缓存的订阅源/站点地图
+func (rs *RedisService) GetCachedFeed(name string) (string, error) {
+	return config.RedisClient.Get(ctx, fmt.Sprintf(FeedCacheKey, name)).Result()
+}
+
+// CacheFeed 缓存订阅源/站点地图，内容变化时会被主动清除，过期时间作为兜底
+func (rs *RedisService) CacheFeed(name, content string) error {
+	return config.RedisClient.Set(ctx, fmt.Sprintf(FeedCacheKey, name), content, time.Hour).Err()
+}
+
+// InvalidateFeedCache 清除所有订阅源与站点地图缓存
+func (rs *RedisService) InvalidateFeedCache() error {
+	return rs.DeleteKeysByPattern(fmt.Sprintf(FeedCacheKey, "*"))
+}
+
+// DeleteKeysByPattern 删除匹配模式的所有键
+func (rs *RedisService) DeleteKeysByPattern(pattern string) error {
+	iter := config.RedisClient.Scan(ctx, 0, pattern, 100).Iterator()
+	var keys []string
+	for iter.Next(ctx) {
+		keys = append(keys, iter.Val())
+	}
+	if err := iter.Err(); err != nil {
+		return err
+	}
+	if len(keys) == 0 {
+		return nil
+	}
+	return config.RedisClient.Del(ctx, keys...).Err()
+}
+
 // ShouldCacheArticle 判断文章是否应该被缓存（阅读量>100）
 func (rs *RedisService) ShouldCacheArticle(articleID uint) (bool, error) {
 	views, err := rs.GetArticleViews(articleID)
@@ -127,17 +474,20 @@ func (rs *RedisService) ShouldCacheArticle(articleID uint) (bool, error) {
 	return views > 100, nil
 }
 
-// StartViewCountSyncTask 启动定时同步阅读量任务
+// StartViewCountSyncTask 启动定时同步阅读量（及点赞等回应计数）任务
 func StartViewCountSyncTask() {
 	ticker := time.NewTicker(5 * time.Minute)
 	defer ticker.Stop()
 
-	log.Println("启动阅读量同步任务，每5分钟执行一次")
+	log.Println("启动阅读量与回应计数同步任务，每5分钟执行一次")
 
 	for range ticker.C {
 		if err := syncViewCountsToDatabase(); err != nil {
 			log.Printf("同步阅读量失败: %v", err)
 		}
+		if err := syncReactionCountsToDatabase(); err != nil {
+			log.Printf("同步回应计数失败: %v", err)
+		}
 	}
 }
 
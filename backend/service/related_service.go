@@ -0,0 +1,230 @@
+package service
+
+import (
+	"backend/config"
+	"backend/dto"
+	"backend/entity"
+	"log"
+	"math"
+	"sort"
+	"time"
+)
+
+// 相关文章得分 = 标签Jaccard相似度 * relatedTagWeight + 内容TF-IDF余弦相似度 * relatedContentWeight
+const (
+	relatedTagWeight     = 0.4
+	relatedContentWeight = 0.6
+	relatedRebuildDelay  = 5 * time.Second // 合并短时间内的多次修改
+)
+
+// relatedScore 相关文章及得分（缓存在Redis中）
+type relatedScore struct {
+	ID    uint    `json:"id"`
+	Score float64 `json:"score"`
+}
+
+// relatedRebuildCh 重新计算相关文章的信号（容量为1，多次修改只触发一次计算）
+var relatedRebuildCh = make(chan struct{}, 1)
+
+// relatedArticleLimit 每篇文章保留的相关文章数
+func relatedArticleLimit() int {
+	return getEnvInt("RELATED_ARTICLE_LIMIT", 5)
+}
+
+// scheduleRelatedRebuild 文章变化后通知后台任务重新计算相关文章
+func scheduleRelatedRebuild() {
+	select {
+	case relatedRebuildCh <- struct{}{}:
+	default:
+	}
+}
+
+// StartRelatedArticleTask 启动相关文章计算任务：启动时计算一次，之后在文章变化时重新计算
+func StartRelatedArticleTask() {
+	log.Println("启动相关文章计算任务")
+
+	rebuildRelatedArticles()
+	for range relatedRebuildCh {
+		time.Sleep(relatedRebuildDelay)
+		// 等待期间的修改已包含在本次计算中
+		select {
+		case <-relatedRebuildCh:
+		default:
+		}
+		rebuildRelatedArticles()
+	}
+}
+
+// relatedCorpus 计算相关度所需的数据：TF-IDF向量（已归一化）与文章标签
+type relatedCorpus struct {
+	vectors map[uint]map[string]float64
+	tags    map[uint]map[uint]struct{}
+}
+
+// loadRelatedCorpus 基于搜索索引（仅包含已发布文章）构造TF-IDF向量，并加载文章标签
+func loadRelatedCorpus() (*relatedCorpus, error) {
+	corpus := &relatedCorpus{
+		vectors: make(map[uint]map[string]float64),
+		tags:    make(map[uint]map[uint]struct{}),
+	}
+
+	searchIndex.mu.RLock()
+	n := float64(len(searchIndex.docs))
+	for id, doc := range searchIndex.docs {
+		vector := make(map[string]float64, len(doc.terms))
+		var norm float64
+		for _, term := range doc.terms {
+			postings := searchIndex.postings[term]
+			idf := math.Log((n+1)/(float64(len(postings))+1)) + 1
+			weight := float64(postings[id]) * idf
+			vector[term] = weight
+			norm += weight * weight
+		}
+		if norm > 0 {
+			norm = math.Sqrt(norm)
+			for term := range vector {
+				vector[term] /= norm
+			}
+		}
+		corpus.vectors[id] = vector
+	}
+	searchIndex.mu.RUnlock()
+
+	var rows []struct {
+		ArticleID uint
+		TagID     uint
+	}
+	if err := config.DB.Table("article_tags").Select("article_id", "tag_id").Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		if corpus.tags[row.ArticleID] == nil {
+			corpus.tags[row.ArticleID] = make(map[uint]struct{})
+		}
+		corpus.tags[row.ArticleID][row.TagID] = struct{}{}
+	}
+	return corpus, nil
+}
+
+// related 计算与指定文章最相关的文章
+func (rc *relatedCorpus) related(id uint, limit int) []relatedScore {
+	vector, ok := rc.vectors[id]
+	if !ok {
+		return []relatedScore{}
+	}
+
+	scores := make([]relatedScore, 0, len(rc.vectors))
+	for otherID, other := range rc.vectors {
+		if otherID == id {
+			continue
+		}
+		score := relatedTagWeight*jaccard(rc.tags[id], rc.tags[otherID]) +
+			relatedContentWeight*cosine(vector, other)
+		if score > 0 {
+			scores = append(scores, relatedScore{ID: otherID, Score: math.Round(score*1000) / 1000})
+		}
+	}
+	sort.Slice(scores, func(i, j int) bool {
+		if scores[i].Score != scores[j].Score {
+			return scores[i].Score > scores[j].Score
+		}
+		return scores[i].ID > scores[j].ID
+	})
+	if len(scores) > limit {
+		scores = scores[:limit]
+	}
+	return scores
+}
+
+// rebuildRelatedArticles 重新计算所有已发布文章的相关文章并写入缓存
+func rebuildRelatedArticles() {
+	corpus, err := loadRelatedCorpus()
+	if err != nil {
+		log.Printf("加载相关文章数据失败: %v", err)
+		return
+	}
+
+	redisService := &RedisService{}
+	limit := relatedArticleLimit()
+	for id := range corpus.vectors {
+		if err := redisService.SaveRelatedArticles(id, corpus.related(id, limit)); err != nil {
+			log.Printf("缓存文章 %d 的相关文章失败: %v", id, err)
+		}
+	}
+	log.Printf("相关文章计算完成，共%d篇文章", len(corpus.vectors))
+}
+
+// GetRelatedArticles 获取与指定文章相关的已发布文章，缓存缺失时即时计算
+func GetRelatedArticles(articleID uint) ([]dto.RelatedArticleItem, error) {
+	var article entity.Article
+	if err := config.DB.Select("id", "status").First(&article, articleID).Error; err != nil {
+		return nil, err
+	}
+	if !isPublicArticle(article) {
+		return nil, ErrArticleNotPublic
+	}
+
+	redisService := &RedisService{}
+	var scores []relatedScore
+	if err := redisService.GetRelatedArticles(articleID, &scores); err != nil {
+		corpus, err := loadRelatedCorpus()
+		if err != nil {
+			return nil, err
+		}
+		scores = corpus.related(articleID, relatedArticleLimit())
+		if err := redisService.SaveRelatedArticles(articleID, scores); err != nil {
+			log.Printf("缓存文章 %d 的相关文章失败: %v", articleID, err)
+		}
+	}
+
+	ids := make([]uint, 0, len(scores))
+	for _, s := range scores {
+		ids = append(ids, s.ID)
+	}
+	items := make([]dto.RelatedArticleItem, 0, len(scores))
+	if len(ids) == 0 {
+		return items, nil
+	}
+
+	var articles []entity.Article
+	if err := config.DB.Preload("Tags").Where("id IN ? AND status = ?", ids, entity.ArticleStatusPublished).
+		Find(&articles).Error; err != nil {
+		return nil, err
+	}
+	articleMap := make(map[uint]entity.Article, len(articles))
+	for _, a := range articles {
+		articleMap[a.ID] = a
+	}
+	for _, s := range scores {
+		if a, ok := articleMap[s.ID]; ok {
+			items = append(items, dto.RelatedArticleItem{ArticleListResponse: mapToListResponse(a), Score: s.Score})
+		}
+	}
+	return items, nil
+}
+
+// jaccard 计算两个标签集合的Jaccard相似度
+func jaccard(a, b map[uint]struct{}) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+	intersection := 0
+	for id := range a {
+		if _, ok := b[id]; ok {
+			intersection++
+		}
+	}
+	return float64(intersection) / float64(len(a)+len(b)-intersection)
+}
+
+// cosine 计算两个已归一化向量的余弦相似度
+func cosine(a, b map[string]float64) float64 {
+	if len(a) > len(b) {
+		a, b = b, a
+	}
+	var dot float64
+	for term, w := range a {
+		dot += w * b[term]
+	}
+	return dot
+}
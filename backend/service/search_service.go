@@ -0,0 +1,466 @@
+package service
+
+import (
+	"backend/config"
+	"backend/dto"
+	"backend/entity"
+	"backend/utils"
+	"html"
+	"log"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// 字段权重：标题 > 摘要 > 正文
+const (
+	searchTitleWeight   = 3
+	searchSummaryWeight = 2
+	searchContentWeight = 1
+	searchSnippetRunes  = 120 // 正文片段长度
+	bm25K1              = 1.2
+	bm25B               = 0.75
+)
+
+// searchDocument 已建立索引的文章
+type searchDocument struct {
+	title   string
+	summary string
+	content string // 去除Markdown后的纯文本
+	length  int    // 加权后的词元总数
+	terms   []string
+}
+
+// articleSearchIndex 文章倒排索引（中文按单字+二元组切分，英文按单词切分）
+type articleSearchIndex struct {
+	mu          sync.RWMutex
+	docs        map[uint]*searchDocument
+	postings    map[string]map[uint]int // 词元 -> 文章ID -> 加权词频
+	totalLength int
+}
+
+var searchIndex = &articleSearchIndex{
+	docs:     make(map[uint]*searchDocument),
+	postings: make(map[string]map[uint]int),
+}
+
+// InitSearchIndex 从数据库加载所有文章建立搜索索引
+func InitSearchIndex() {
+	var articles []entity.Article
+	if err := config.DB.Select("id", "title", "summary", "content").
+		Where("status = ?", entity.ArticleStatusPublished).Find(&articles).Error; err != nil {
+		log.Printf("建立文章搜索索引失败: %v", err)
+		return
+	}
+	for _, article := range articles {
+		searchIndex.index(article)
+	}
+	log.Printf("文章搜索索引建立完成，共%d篇文章", len(articles))
+}
+
+// syncSearchIndex 按文章状态同步搜索索引：只有已发布的文章可被搜索
+func syncSearchIndex(article entity.Article) {
+	if isPublicArticle(article) {
+		searchIndex.index(article)
+	} else {
+		searchIndex.remove(article.ID)
+	}
+}
+
+// NotifyArticlesReindex 通知运行中的服务重新索引指定文章（搜索索引只在各进程内存中，其他进程修改文章后需调用）
+func NotifyArticlesReindex(articleIDs []uint) error {
+	ids := make([]string, 0, len(articleIDs))
+	for _, id := range articleIDs {
+		ids = append(ids, strconv.FormatUint(uint64(id), 10))
+	}
+	return config.RedisClient.Publish(ctx, ArticleReindexChannel, strings.Join(ids, ",")).Err()
+}
+
+// StartArticleReindexListener 订阅文章重新索引通知，同步本进程的搜索索引并重新计算相关文章
+func StartArticleReindexListener() {
+	log.Println("启动文章索引同步任务")
+
+	pubsub := config.RedisClient.Subscribe(ctx, ArticleReindexChannel)
+	defer pubsub.Close()
+	for msg := range pubsub.Channel() {
+		reindexArticles(parseArticleIDs(msg.Payload))
+	}
+}
+
+// reindexArticles 从数据库重新加载文章并同步搜索索引，已删除的文章从索引中移除
+func reindexArticles(articleIDs []uint) {
+	if len(articleIDs) == 0 {
+		return
+	}
+
+	var articles []entity.Article
+	if err := config.DB.Select("id", "title", "summary", "content", "status").
+		Where("id IN ?", articleIDs).Find(&articles).Error; err != nil {
+		log.Printf("同步文章搜索索引失败: %v", err)
+		return
+	}
+	found := make(map[uint]bool, len(articles))
+	for _, article := range articles {
+		syncSearchIndex(article)
+		found[article.ID] = true
+	}
+	for _, id := range articleIDs {
+		if !found[id] {
+			searchIndex.remove(id)
+		}
+	}
+	scheduleRelatedRebuild()
+	log.Printf("已同步%d篇文章的搜索索引", len(articleIDs))
+}
+
+// parseArticleIDs 解析逗号分隔的文章ID，忽略无效项
+func parseArticleIDs(payload string) []uint {
+	var ids []uint
+	for _, field := range strings.Split(payload, ",") {
+		id, err := strconv.ParseUint(strings.TrimSpace(field), 10, 32)
+		if err != nil || id == 0 {
+			continue
+		}
+		ids = append(ids, uint(id))
+	}
+	return ids
+}
+
+// index 建立或更新文章索引
+func (idx *articleSearchIndex) index(article entity.Article) {
+	doc := &searchDocument{
+		title:   article.Title,
+		summary: utils.StripMarkdown(article.Summary),
+		content: utils.StripMarkdown(article.Content),
+	}
+
+	freq := make(map[string]int)
+	for _, field := range []struct {
+		text   string
+		weight int
+	}{
+		{doc.title, searchTitleWeight},
+		{doc.summary, searchSummaryWeight},
+		{doc.content, searchContentWeight},
+	} {
+		for _, term := range tokenize(field.text, false) {
+			freq[term] += field.weight
+			doc.length += field.weight
+		}
+	}
+
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	idx.removeLocked(article.ID)
+	for term, tf := range freq {
+		if idx.postings[term] == nil {
+			idx.postings[term] = make(map[uint]int)
+		}
+		idx.postings[term][article.ID] = tf
+		doc.terms = append(doc.terms, term)
+	}
+	idx.docs[article.ID] = doc
+	idx.totalLength += doc.length
+}
+
+// remove 从索引中移除文章
+func (idx *articleSearchIndex) remove(articleID uint) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	idx.removeLocked(articleID)
+}
+
+// removeLocked 移除文章（调用方需持有写锁）
+func (idx *articleSearchIndex) removeLocked(articleID uint) {
+	doc, ok := idx.docs[articleID]
+	if !ok {
+		return
+	}
+	for _, term := range doc.terms {
+		delete(idx.postings[term], articleID)
+		if len(idx.postings[term]) == 0 {
+			delete(idx.postings, term)
+		}
+	}
+	idx.totalLength -= doc.length
+	delete(idx.docs, articleID)
+}
+
+// searchHit 搜索命中结果
+type searchHit struct {
+	id    uint
+	score float64
+}
+
+// search 按BM25计算相关度，返回按得分降序的结果
+func (idx *articleSearchIndex) search(query string) []searchHit {
+	terms := uniqueStrings(tokenize(query, true))
+	if len(terms) == 0 {
+		return nil
+	}
+
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	n := float64(len(idx.docs))
+	if n == 0 {
+		return nil
+	}
+	avgLength := float64(idx.totalLength) / n
+
+	scores := make(map[uint]float64)
+	matched := make(map[uint]int)
+	for _, term := range terms {
+		postings := idx.postings[term]
+		if len(postings) == 0 {
+			continue
+		}
+		df := float64(len(postings))
+		idf := math.Log(1 + (n-df+0.5)/(df+0.5))
+		for id, tf := range postings {
+			length := float64(idx.docs[id].length)
+			norm := float64(tf) * (bm25K1 + 1) / (float64(tf) + bm25K1*(1-bm25B+bm25B*length/avgLength))
+			scores[id] += idf * norm
+			matched[id]++
+		}
+	}
+
+	// 标题完整包含查询词时额外加分
+	phrase := strings.ToLower(strings.TrimSpace(query))
+	hits := make([]searchHit, 0, len(scores))
+	for id, score := range scores {
+		// 命中的查询词元越多越靠前
+		score *= float64(matched[id]) / float64(len(terms))
+		if strings.Contains(strings.ToLower(idx.docs[id].title), phrase) {
+			score *= 1.5
+		}
+		hits = append(hits, searchHit{id: id, score: score})
+	}
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].score != hits[j].score {
+			return hits[i].score > hits[j].score
+		}
+		return hits[i].id > hits[j].id
+	})
+	return hits
+}
+
+// highlight 生成高亮标题与正文片段
+func (idx *articleSearchIndex) highlight(articleID uint, query string) (title, snippet string) {
+	idx.mu.RLock()
+	doc, ok := idx.docs[articleID]
+	idx.mu.RUnlock()
+	if !ok {
+		return "", ""
+	}
+
+	terms := highlightTerms(query)
+	title = markTerms([]rune(doc.title), terms)
+
+	content := []rune(doc.content)
+	start := firstMatch(content, terms)
+	if start < 0 {
+		start = 0
+	} else {
+		start = maxInt(0, start-searchSnippetRunes/4)
+	}
+	end := minInt(len(content), start+searchSnippetRunes)
+	snippet = markTerms(content[start:end], terms)
+	if start > 0 {
+		snippet = "..." + snippet
+	}
+	if end < len(content) {
+		snippet += "..."
+	}
+	return title, snippet
+}
+
+// SearchArticles 全文搜索文章（标题、摘要、正文），按相关度排序并分页
+func SearchArticles(query string, page, pageSize int) (*dto.PageResponse, error) {
+	hits := searchIndex.search(query)
+
+	resp := &dto.PageResponse{
+		Total:    int64(len(hits)),
+		Page:     page,
+		PageSize: pageSize,
+	}
+	offset := (page - 1) * pageSize
+	if offset >= len(hits) {
+		resp.List = []dto.ArticleSearchItem{}
+		return resp, nil
+	}
+	hits = hits[offset:minInt(len(hits), offset+pageSize)]
+
+	ids := make([]uint, 0, len(hits))
+	for _, hit := range hits {
+		ids = append(ids, hit.id)
+	}
+	var articles []entity.Article
+	if err := config.DB.Preload("Tags").Where("id IN ?", ids).Find(&articles).Error; err != nil {
+		return nil, err
+	}
+	articleMap := make(map[uint]entity.Article, len(articles))
+	for _, a := range articles {
+		articleMap[a.ID] = a
+	}
+
+	items := make([]dto.ArticleSearchItem, 0, len(hits))
+	for _, hit := range hits {
+		article, ok := articleMap[hit.id]
+		if !ok {
+			continue
+		}
+		title, snippet := searchIndex.highlight(hit.id, query)
+		items = append(items, dto.ArticleSearchItem{
+			ArticleListResponse: mapToListResponse(article),
+			Score:               math.Round(hit.score*1000) / 1000,
+			HighlightTitle:      title,
+			Snippet:             snippet,
+		})
+	}
+	resp.List = items
+	return resp, nil
+}
+
+// tokenize 切分词元：连续的汉字切分为二元组（建索引时同时保留单字），其余按字母数字切分为单词
+func tokenize(text string, query bool) []string {
+	var tokens []string
+	var word, han []rune
+
+	flushWord := func() {
+		if len(word) > 0 {
+			tokens = append(tokens, string(word))
+			word = word[:0]
+		}
+	}
+	flushHan := func() {
+		if len(han) == 0 {
+			return
+		}
+		// 查询时单个汉字才使用单字匹配，否则只使用二元组
+		if !query || len(han) == 1 {
+			for _, r := range han {
+				tokens = append(tokens, string(r))
+			}
+		}
+		for i := 0; i+1 < len(han); i++ {
+			tokens = append(tokens, string(han[i:i+2]))
+		}
+		han = han[:0]
+	}
+
+	for _, r := range strings.ToLower(text) {
+		switch {
+		case unicode.Is(unicode.Han, r):
+			flushWord()
+			han = append(han, r)
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			flushHan()
+			word = append(word, r)
+		default:
+			flushWord()
+			flushHan()
+		}
+	}
+	flushWord()
+	flushHan()
+	return tokens
+}
+
+// highlightTerms 需要高亮的词：查询中的关键词及其中文二元组，长的优先
+func highlightTerms(query string) [][]rune {
+	terms := uniqueStrings(append(strings.Fields(strings.ToLower(query)), tokenize(query, true)...))
+	sort.Slice(terms, func(i, j int) bool { return len([]rune(terms[i])) > len([]rune(terms[j])) })
+
+	result := make([][]rune, 0, len(terms))
+	for _, t := range terms {
+		result = append(result, []rune(t))
+	}
+	return result
+}
+
+// matchAt 判断text在位置i处是否（忽略大小写）匹配term
+func matchAt(text []rune, i int, term []rune) bool {
+	if i+len(term) > len(text) {
+		return false
+	}
+	for j, r := range term {
+		if unicode.ToLower(text[i+j]) != r {
+			return false
+		}
+	}
+	return true
+}
+
+// firstMatch 第一个匹配位置，没有匹配返回-1
+func firstMatch(text []rune, terms [][]rune) int {
+	for i := range text {
+		for _, term := range terms {
+			if len(term) > 0 && matchAt(text, i, term) {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// markTerms HTML转义文本，并用<mark>包裹匹配的词
+func markTerms(text []rune, terms [][]rune) string {
+	var sb strings.Builder
+	last := 0
+	for i := 0; i < len(text); {
+		matched := 0
+		for _, term := range terms {
+			if len(term) > 0 && matchAt(text, i, term) {
+				matched = len(term)
+				break
+			}
+		}
+		if matched == 0 {
+			i++
+			continue
+		}
+		sb.WriteString(html.EscapeString(string(text[last:i])))
+		sb.WriteString("<mark>")
+		sb.WriteString(html.EscapeString(string(text[i : i+matched])))
+		sb.WriteString("</mark>")
+		i += matched
+		last = i
+	}
+	sb.WriteString(html.EscapeString(string(text[last:])))
+	return sb.String()
+}
+
+// uniqueStrings 去重并保持顺序
+func uniqueStrings(items []string) []string {
+	seen := make(map[string]struct{}, len(items))
+	result := make([]string, 0, len(items))
+	for _, item := range items {
+		if _, ok := seen[item]; ok {
+			continue
+		}
+		seen[item] = struct{}{}
+		result = append(result, item)
+	}
+	return result
+}
+
+// minInt 返回较小值
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+// maxInt 返回较大值
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
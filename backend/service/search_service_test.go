@@ -0,0 +1,171 @@
+package service
+
+import (
+	"backend/entity"
+	"math"
+	"reflect"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+func TestTokenize(t *testing.T) {
+	tests := []struct {
+		name  string
+		text  string
+		query bool
+		want  []string
+	}{
+		{"英文单词转小写", "Hello, Go-Lang 1.22", false, []string{"hello", "go", "lang", "1", "22"}},
+		{"建索引时保留单字与二元组", "语言入门", false, []string{"语", "言", "入", "门", "语言", "言入", "入门"}},
+		{"查询时只用二元组", "语言入门", true, []string{"语言", "言入", "入门"}},
+		{"查询单个汉字", "树", true, []string{"树"}},
+		{"中英混合", "Go语言", true, []string{"go", "语言"}},
+		{"标点分隔汉字", "二叉树，遍历", true, []string{"二叉", "叉树", "遍历"}},
+		{"非中文字母", "Café naïve", false, []string{"café", "naïve"}},
+		{"只有符号", "!@# ，。", false, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tokenize(tt.text, tt.query); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("tokenize(%q, %v) = %q, want %q", tt.text, tt.query, got, tt.want)
+			}
+		})
+	}
+}
+
+// newTestSearchIndex 用给定的文章建立独立的索引
+func newTestSearchIndex(articles ...entity.Article) *articleSearchIndex {
+	idx := &articleSearchIndex{
+		docs:     make(map[uint]*searchDocument),
+		postings: make(map[string]map[uint]int),
+	}
+	for _, article := range articles {
+		idx.index(article)
+	}
+	return idx
+}
+
+func searchArticle(id uint, title, summary, content string) entity.Article {
+	return entity.Article{Model: gorm.Model{ID: id}, Title: title, Summary: summary, Content: content}
+}
+
+func hitIDs(hits []searchHit) []uint {
+	ids := make([]uint, 0, len(hits))
+	for _, hit := range hits {
+		ids = append(ids, hit.id)
+	}
+	return ids
+}
+
+func TestSearchRanking(t *testing.T) {
+	idx := newTestSearchIndex(
+		searchArticle(1, "Go 并发编程", "", "goroutine 与 channel"),
+		searchArticle(2, "Rust 入门", "顺带对比 Go", "所有权与借用"),
+		searchArticle(3, "数据库索引", "", "B+树索引的原理，顺带提到 go 驱动"),
+		searchArticle(4, "二叉树遍历", "", "前序、中序与后序遍历"),
+		searchArticle(5, "二叉搜索树", "", "查找与插入"),
+	)
+	tests := []struct {
+		name  string
+		query string
+		want  []uint
+	}{
+		{"标题优先于摘要和正文", "go", []uint{1, 2, 3}},
+		{"大小写不敏感", "GO", []uint{1, 2, 3}},
+		{"命中更多词元的靠前", "二叉树遍历", []uint{4, 5}},
+		{"中文二元组", "索引", []uint{3}},
+		// 标题都含「树」时，加权长度更短的文章得分更高
+		{"单个汉字", "树", []uint{5, 4, 3}},
+		{"没有命中", "python", []uint{}},
+		{"空查询", "  ，", []uint{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := hitIDs(idx.search(tt.query)); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("search(%q) = %v, want %v", tt.query, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSearchBM25Score(t *testing.T) {
+	idx := newTestSearchIndex(
+		searchArticle(1, "go", "", ""),
+		searchArticle(2, "rust", "", ""),
+	)
+	hits := idx.search("go")
+	if len(hits) != 1 || hits[0].id != 1 {
+		t.Fatalf("search(go) = %v", hits)
+	}
+	// 两篇文章加权长度都为3，标题词频为3；标题完整包含查询词再乘1.5
+	idf := math.Log(1 + (2-1+0.5)/(1+0.5))
+	norm := 3 * (bm25K1 + 1) / (3 + bm25K1)
+	if want := idf * norm * 1.5; math.Abs(hits[0].score-want) > 1e-9 {
+		t.Errorf("score = %v, want %v", hits[0].score, want)
+	}
+}
+
+func TestSearchIndexUpdate(t *testing.T) {
+	idx := newTestSearchIndex(searchArticle(1, "旧标题", "", "kubernetes"), searchArticle(2, "其他", "", "docker"))
+
+	// 重新索引后旧内容不再命中，且统计的总长度不重复累计
+	idx.index(searchArticle(1, "新标题", "", "docker"))
+	if got := hitIDs(idx.search("kubernetes")); len(got) != 0 {
+		t.Errorf("更新后仍命中旧内容: %v", got)
+	}
+	if got := hitIDs(idx.search("docker")); !reflect.DeepEqual(got, []uint{2, 1}) && !reflect.DeepEqual(got, []uint{1, 2}) {
+		t.Errorf("search(docker) = %v", got)
+	}
+	wantLength := idx.docs[1].length + idx.docs[2].length
+	if idx.totalLength != wantLength {
+		t.Errorf("totalLength = %d, want %d", idx.totalLength, wantLength)
+	}
+
+	idx.remove(1)
+	idx.remove(1)
+	if got := hitIDs(idx.search("docker")); !reflect.DeepEqual(got, []uint{2}) {
+		t.Errorf("删除后 search(docker) = %v, want [2]", got)
+	}
+	if _, ok := idx.postings["新标"]; ok {
+		t.Error("删除文章后应清除只属于它的词元")
+	}
+	if idx.totalLength != idx.docs[2].length {
+		t.Errorf("totalLength = %d, want %d", idx.totalLength, idx.docs[2].length)
+	}
+}
+
+func TestMarkTerms(t *testing.T) {
+	tests := []struct {
+		text  string
+		query string
+		want  string
+	}{
+		{"Go 语言入门", "go 入门", "<mark>Go</mark> 语言<mark>入门</mark>"},
+		{"<b>Go</b>", "go", "&lt;b&gt;<mark>Go</mark>&lt;/b&gt;"},
+		{"二叉树遍历", "二叉树", "<mark>二叉树</mark>遍历"},
+		{"没有命中", "rust", "没有命中"},
+	}
+	for _, tt := range tests {
+		if got := markTerms([]rune(tt.text), highlightTerms(tt.query)); got != tt.want {
+			t.Errorf("markTerms(%q, %q) = %q, want %q", tt.text, tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestParseArticleIDs(t *testing.T) {
+	tests := []struct {
+		payload string
+		want    []uint
+	}{
+		{"1,2,3", []uint{1, 2, 3}},
+		{" 7 , 8", []uint{7, 8}},
+		{"1,,abc,0,-2,5", []uint{1, 5}},
+		{"", nil},
+	}
+	for _, tt := range tests {
+		if got := parseArticleIDs(tt.payload); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("parseArticleIDs(%q) = %v, want %v", tt.payload, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,270 @@
+package service
+
+import (
+	"backend/config"
+	"backend/dto"
+	"backend/entity"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// ErrSeriesArticleNotFound 系列中包含不存在的文章
+var ErrSeriesArticleNotFound = errors.New("系列中包含不存在的文章")
+
+// CreateSeries 创建系列，articleIds为按阅读顺序排列的文章
+func CreateSeries(req dto.SeriesCreateRequest) (*dto.SeriesResponse, error) {
+	series := entity.Series{
+		Title:       req.Title,
+		Description: req.Description,
+	}
+
+	var affected []uint
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&series).Error; err != nil {
+			return err
+		}
+		var err error
+		affected, err = setSeriesArticlesTx(tx, series.ID, req.ArticleIds)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	invalidateArticleCache(affected...)
+	return GetSeriesByID(series.ID, false)
+}
+
+// GetAllSeries 获取所有系列（不含文章列表），articleCount为已发布的文章数
+func GetAllSeries() ([]dto.SeriesResponse, error) {
+	var seriesList []entity.Series
+	if err := config.DB.Order("id desc").Find(&seriesList).Error; err != nil {
+		return nil, err
+	}
+
+	var counts []struct {
+		SeriesID uint
+		Count    int
+	}
+	if err := config.DB.Model(&entity.Article{}).Select("series_id, COUNT(*) AS count").
+		Where("series_id IS NOT NULL AND status = ?", entity.ArticleStatusPublished).
+		Group("series_id").Scan(&counts).Error; err != nil {
+		return nil, err
+	}
+	countMap := make(map[uint]int, len(counts))
+	for _, c := range counts {
+		countMap[c.SeriesID] = c.Count
+	}
+
+	responses := make([]dto.SeriesResponse, 0, len(seriesList))
+	for _, series := range seriesList {
+		resp := mapSeriesToResponse(series)
+		resp.ArticleCount = countMap[series.ID]
+		responses = append(responses, resp)
+	}
+	return responses, nil
+}
+
+// GetSeriesByID 获取系列详情及其中按顺序排列的文章，public为true时只包含已发布的文章
+func GetSeriesByID(id uint, public bool) (*dto.SeriesResponse, error) {
+	var series entity.Series
+	if err := config.DB.First(&series, id).Error; err != nil {
+		return nil, err
+	}
+
+	query := config.DB.Preload("Tags").Where("series_id = ?", id)
+	if public {
+		query = query.Where("status = ?", entity.ArticleStatusPublished)
+	}
+	var articles []entity.Article
+	if err := query.Order("series_order asc, id asc").Find(&articles).Error; err != nil {
+		return nil, err
+	}
+
+	resp := mapSeriesToResponse(series)
+	resp.ArticleCount = len(articles)
+	resp.Articles = make([]dto.ArticleListResponse, 0, len(articles))
+	for _, article := range articles {
+		resp.Articles = append(resp.Articles, mapToListResponse(article))
+	}
+	return &resp, nil
+}
+
+// UpdateSeries 更新系列信息，articleIds不为nil时按新顺序替换系列中的文章
+func UpdateSeries(id uint, req dto.SeriesUpdateRequest) (*dto.SeriesResponse, error) {
+	var affected []uint
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		var series entity.Series
+		if err := tx.First(&series, id).Error; err != nil {
+			return err
+		}
+		// 系列标题会显示在其中每篇文章的详情中
+		if err := tx.Model(&entity.Article{}).Where("series_id = ?", id).Pluck("id", &affected).Error; err != nil {
+			return err
+		}
+		if req.Title != nil {
+			if strings.TrimSpace(*req.Title) == "" {
+				return fmt.Errorf("标题%w", ErrPatchFieldRequired)
+			}
+			series.Title = *req.Title
+		}
+		if req.Description != nil {
+			series.Description = *req.Description
+		}
+		if err := tx.Save(&series).Error; err != nil {
+			return err
+		}
+		if req.ArticleIds == nil {
+			return nil
+		}
+		changed, err := setSeriesArticlesTx(tx, id, req.ArticleIds)
+		affected = append(affected, changed...)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	invalidateArticleCache(uniqueUints(affected)...)
+	return GetSeriesByID(id, false)
+}
+
+// DeleteSeries 删除系列，其中的文章保留但不再属于任何系列
+func DeleteSeries(id uint) error {
+	var affected []uint
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		var series entity.Series
+		if err := tx.First(&series, id).Error; err != nil {
+			return err
+		}
+		var err error
+		if affected, err = setSeriesArticlesTx(tx, id, nil); err != nil {
+			return err
+		}
+		return tx.Delete(&series).Error
+	})
+	if err != nil {
+		return err
+	}
+	invalidateArticleCache(affected...)
+	return nil
+}
+
+// setSeriesArticlesTx 按给定顺序设置系列中的文章；已属于其他系列的文章会被移到该系列。
+// 返回系列信息（上一篇/下一篇等）可能变化的文章：该系列原有的文章、新加入的文章及其原系列中的文章
+func setSeriesArticlesTx(tx *gorm.DB, seriesID uint, articleIds []uint) ([]uint, error) {
+	articleIds = uniqueUints(articleIds)
+	if len(articleIds) > 0 {
+		var count int64
+		if err := tx.Model(&entity.Article{}).Where("id IN ?", articleIds).Count(&count).Error; err != nil {
+			return nil, err
+		}
+		if int(count) != len(articleIds) {
+			return nil, ErrSeriesArticleNotFound
+		}
+	}
+
+	seriesIds := []uint{seriesID}
+	if len(articleIds) > 0 {
+		var previous []uint
+		if err := tx.Model(&entity.Article{}).Where("id IN ? AND series_id IS NOT NULL", articleIds).
+			Distinct().Pluck("series_id", &previous).Error; err != nil {
+			return nil, err
+		}
+		seriesIds = append(seriesIds, previous...)
+	}
+	var affected []uint
+	if err := tx.Model(&entity.Article{}).Where("series_id IN ?", uniqueUints(seriesIds)).Pluck("id", &affected).Error; err != nil {
+		return nil, err
+	}
+
+	// 系列归属不影响文章的更新时间
+	if err := tx.Model(&entity.Article{}).Where("series_id = ?", seriesID).
+		UpdateColumns(map[string]interface{}{"series_id": nil, "series_order": 0}).Error; err != nil {
+		return nil, err
+	}
+	for i, articleID := range articleIds {
+		if err := tx.Model(&entity.Article{}).Where("id = ?", articleID).
+			UpdateColumns(map[string]interface{}{"series_id": seriesID, "series_order": i + 1}).Error; err != nil {
+			return nil, err
+		}
+	}
+	return uniqueUints(append(affected, articleIds...)), nil
+}
+
+// getArticleSeriesInfo 获取文章所属系列信息及上一篇/下一篇（只在已发布文章间导航）
+func getArticleSeriesInfo(article entity.Article) (*dto.ArticleSeriesInfo, error) {
+	if article.SeriesID == nil {
+		return nil, nil
+	}
+
+	var series entity.Series
+	if err := config.DB.First(&series, *article.SeriesID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var siblings []entity.Article
+	if err := config.DB.Select("id", "title", "slug").
+		Where("series_id = ? AND (status = ? OR id = ?)", series.ID, entity.ArticleStatusPublished, article.ID).
+		Order("series_order asc, id asc").Find(&siblings).Error; err != nil {
+		return nil, err
+	}
+
+	info := &dto.ArticleSeriesInfo{ID: series.ID, Title: series.Title, Total: len(siblings)}
+	for i, sibling := range siblings {
+		if sibling.ID != article.ID {
+			continue
+		}
+		info.Position = i + 1
+		if i > 0 {
+			info.Prev = mapSeriesNavItem(siblings[i-1])
+		}
+		if i+1 < len(siblings) {
+			info.Next = mapSeriesNavItem(siblings[i+1])
+		}
+		break
+	}
+	if !isPublicArticle(article) {
+		// 未发布的文章不计入总数
+		info.Total--
+	}
+	return info, nil
+}
+
+// withSeriesSiblings 返回文章及其所在系列中的其他文章（包括已删除的文章原来所在的系列），
+// 系列中每篇文章的详情都包含同系列文章的标题、上一篇/下一篇与总数，任一篇变化时都需要清除缓存
+func withSeriesSiblings(articleIDs []uint) []uint {
+	if len(articleIDs) == 0 {
+		return articleIDs
+	}
+	seriesIds := config.DB.Unscoped().Model(&entity.Article{}).Select("series_id").
+		Where("id IN ? AND series_id IS NOT NULL", articleIDs)
+	var siblings []uint
+	if err := config.DB.Model(&entity.Article{}).Where("series_id IN (?)", seriesIds).
+		Pluck("id", &siblings).Error; err != nil {
+		log.Printf("查询系列中的文章失败: %v", err)
+		return articleIDs
+	}
+	return uniqueUints(append(articleIDs, siblings...))
+}
+
+// mapSeriesNavItem 转换为系列导航项
+func mapSeriesNavItem(article entity.Article) *dto.SeriesNavItem {
+	return &dto.SeriesNavItem{ID: article.ID, Title: article.Title, Slug: article.Slug}
+}
+
+// mapSeriesToResponse 将系列实体转换为响应 DTO
+func mapSeriesToResponse(series entity.Series) dto.SeriesResponse {
+	return dto.SeriesResponse{
+		ID:          series.ID,
+		Title:       series.Title,
+		Description: series.Description,
+		CreatedAt:   series.CreatedAt.Format("2006-01-02 15:04:05"),
+		UpdatedAt:   series.UpdatedAt.Format("2006-01-02 15:04:05"),
+	}
+}
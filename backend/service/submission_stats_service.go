@@ -0,0 +1,287 @@
+package service
+
+import (
+	"backend/config"
+	"backend/dto"
+	"backend/entity"
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// memoryBucketKB 内存直方图的桶宽(KB)，执行时间按毫秒精确统计
+const memoryBucketKB = 64
+
+// 题目+语言还没有通过的提交时，在执行时间直方图中写入占位字段并设置过期时间，避免每次查询都回源数据库
+const (
+	perfEmptyField = "empty"
+	perfEmptyTTL   = 10 * time.Minute
+)
+
+// 判题与重建并发时用于去重的辅助键（题目:语言）
+const (
+	ojPerfRecentKey  = "oj:perf:%d:%s:recent"  // 最近判题通过的提交（有序集合，成员为 提交ID|执行时间|内存桶，分数为记录时间）
+	ojPerfCountedKey = "oj:perf:%d:%s:counted" // 最近已计入分布的提交ID（有序集合，分数为记录时间）
+)
+
+// perfRecentWindow 判题结果写入数据库到计入分布之间最长的间隔，重建时只需核对这段时间内的提交
+const perfRecentWindow = 5 * time.Minute
+
+// perfRecordScript 原子地把一次通过的提交计入分布：先记入最近提交，分布未构建时返回0（由调用方重建），
+// 已计入（如重建时已从数据库读到）时返回2，否则计入并返回1。
+// KEYS: 执行时间直方图、内存直方图、最近提交、已计入的提交；ARGV: 提交ID、执行时间、内存桶、当前时间、保留秒数、空分布占位字段
+var perfRecordScript = redis.NewScript(`
+local cutoff = tonumber(ARGV[4]) - tonumber(ARGV[5])
+redis.call('ZADD', KEYS[3], ARGV[4], ARGV[1] .. '|' .. ARGV[2] .. '|' .. ARGV[3])
+redis.call('ZREMRANGEBYSCORE', KEYS[3], '-inf', cutoff)
+redis.call('EXPIRE', KEYS[3], ARGV[5])
+if redis.call('EXISTS', KEYS[1]) == 0 then
+	return 0
+end
+if redis.call('ZSCORE', KEYS[4], ARGV[1]) then
+	return 2
+end
+redis.call('HINCRBY', KEYS[1], ARGV[2], 1)
+redis.call('HINCRBY', KEYS[2], ARGV[3], 1)
+redis.call('HDEL', KEYS[1], ARGV[6])
+redis.call('PERSIST', KEYS[1])
+redis.call('ZADD', KEYS[4], ARGV[4], ARGV[1])
+redis.call('ZREMRANGEBYSCORE', KEYS[4], '-inf', cutoff)
+redis.call('EXPIRE', KEYS[4], ARGV[5])
+return 1
+`)
+
+// perfReconcileScript 重建后补记最近通过但不在数据库快照中的提交（重建读取数据库之后才提交的判题结果），返回补记的数量。
+// KEYS: 执行时间直方图、内存直方图、最近提交、已计入的提交；ARGV: 当前时间、保留秒数、空分布占位字段
+var perfReconcileScript = redis.NewScript(`
+local cutoff = tonumber(ARGV[1]) - tonumber(ARGV[2])
+redis.call('ZREMRANGEBYSCORE', KEYS[3], '-inf', cutoff)
+local added = 0
+for _, member in ipairs(redis.call('ZRANGE', KEYS[3], 0, -1)) do
+	local id, t, m = string.match(member, '^(%d+)|(-?%d+)|(-?%d+)$')
+	if id and not redis.call('ZSCORE', KEYS[4], id) then
+		redis.call('HINCRBY', KEYS[1], t, 1)
+		redis.call('HINCRBY', KEYS[2], m, 1)
+		redis.call('ZADD', KEYS[4], ARGV[1], id)
+		added = added + 1
+	end
+end
+if added > 0 then
+	redis.call('HDEL', KEYS[1], ARGV[3])
+	redis.call('PERSIST', KEYS[1])
+	redis.call('EXPIRE', KEYS[4], ARGV[2])
+end
+return added
+`)
+
+// perfKeys 获取题目+语言的执行时间与内存直方图键
+func perfKeys(problemID uint, language string) (timeKey, memoryKey string) {
+	return fmt.Sprintf(OJPerfTimeKey, problemID, language), fmt.Sprintf(OJPerfMemoryKey, problemID, language)
+}
+
+// invalidateProblemStats 题目的提交记录被删除或恢复后清除其执行时间/内存分布，并让排行榜重建
+func invalidateProblemStats(problemID uint) {
+	redisService := &RedisService{}
+	if err := redisService.DeleteKeysByPattern(fmt.Sprintf("oj:perf:%d:*", problemID)); err != nil {
+		log.Printf("清除题目 %d 的提交分布失败: %v", problemID, err)
+	}
+	if err := invalidateLeaderboards(); err != nil {
+		log.Printf("清除排行榜失败: %v", err)
+	}
+}
+
+// memoryBucket 内存使用所在的桶
+func memoryBucket(memoryKB int) int {
+	return memoryKB / memoryBucketKB * memoryBucketKB
+}
+
+// perfSyncKeys 分布相关的全部键：执行时间直方图、内存直方图、最近提交、已计入的提交
+func perfSyncKeys(problemID uint, language string) []string {
+	timeKey, memoryKey := perfKeys(problemID, language)
+	return []string{timeKey, memoryKey,
+		fmt.Sprintf(ojPerfRecentKey, problemID, language), fmt.Sprintf(ojPerfCountedKey, problemID, language)}
+}
+
+// recordAcceptedPerformance 将通过的提交计入执行时间/内存分布（与重建并发时不会重复计入）
+func recordAcceptedPerformance(submission *entity.Submission) error {
+	if submission.Status != "ACCEPTED" {
+		return nil
+	}
+	recorded, err := perfRecordScript.Run(ctx, config.RedisClient, perfSyncKeys(submission.ProblemID, submission.Language),
+		submission.ID, submission.ExecuteTime, memoryBucket(submission.MemoryUsage),
+		time.Now().Unix(), int64(perfRecentWindow/time.Second), perfEmptyField).Int()
+	if err != nil {
+		return err
+	}
+	if recorded == 0 {
+		// 分布尚未构建，从数据库重建（已包含本次提交）
+		return rebuildPerformanceHistogram(submission.ProblemID, submission.Language)
+	}
+	return nil
+}
+
+// rebuildPerformanceHistogram 从数据库重建题目+语言的执行时间与内存分布
+func rebuildPerformanceHistogram(problemID uint, language string) error {
+	keys := perfSyncKeys(problemID, language)
+	timeKey, memoryKey, countedKey := keys[0], keys[1], keys[3]
+
+	var rows []struct {
+		ID          uint
+		ExecuteTime int
+		MemoryUsage int
+		UpdatedAt   time.Time
+	}
+	if err := config.DB.Model(&entity.Submission{}).Select("id, execute_time, memory_usage, updated_at").
+		Where("problem_id = ? AND language = ? AND status = ?", problemID, language, "ACCEPTED").
+		Scan(&rows).Error; err != nil {
+		return err
+	}
+
+	// 最近判题通过的提交记为已计入，之后补记时只补快照中没有的提交
+	now := time.Now()
+	recentSince := now.Add(-2 * perfRecentWindow)
+	timeHist := make(map[string]interface{})
+	memoryHist := make(map[string]interface{})
+	var counted []*redis.Z
+	for _, row := range rows {
+		t := strconv.Itoa(row.ExecuteTime)
+		m := strconv.Itoa(memoryBucket(row.MemoryUsage))
+		timeHist[t] = toInt64(timeHist[t]) + 1
+		memoryHist[m] = toInt64(memoryHist[m]) + 1
+		if row.UpdatedAt.After(recentSince) {
+			counted = append(counted, &redis.Z{Score: float64(now.Unix()), Member: row.ID})
+		}
+	}
+
+	pipe := config.RedisClient.TxPipeline()
+	pipe.Del(ctx, timeKey, memoryKey, countedKey)
+	if len(rows) > 0 {
+		pipe.HSet(ctx, timeKey, timeHist)
+		pipe.HSet(ctx, memoryKey, memoryHist)
+	} else {
+		pipe.HSet(ctx, timeKey, perfEmptyField, 0)
+		pipe.Expire(ctx, timeKey, perfEmptyTTL)
+	}
+	if len(counted) > 0 {
+		pipe.ZAdd(ctx, countedKey, counted...)
+		pipe.Expire(ctx, countedKey, perfRecentWindow)
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return err
+	}
+	return perfReconcileScript.Run(ctx, config.RedisClient, keys,
+		now.Unix(), int64(perfRecentWindow/time.Second), perfEmptyField).Err()
+}
+
+// toInt64 直方图计数转换
+func toInt64(v interface{}) int64 {
+	n, _ := v.(int64)
+	return n
+}
+
+// loadHistogram 读取直方图，返回按取值升序的桶
+func loadHistogram(key string) ([]dto.HistogramBucket, int64, error) {
+	raw, err := config.RedisClient.HGetAll(ctx, key).Result()
+	if err != nil && err != redis.Nil {
+		return nil, 0, err
+	}
+
+	buckets := make([]dto.HistogramBucket, 0, len(raw))
+	var total int64
+	for k, v := range raw {
+		value, err1 := strconv.Atoi(k)
+		count, err2 := strconv.ParseInt(v, 10, 64)
+		if err1 != nil || err2 != nil {
+			continue
+		}
+		buckets = append(buckets, dto.HistogramBucket{Value: value, Count: count})
+		total += count
+	}
+	sort.Slice(buckets, func(i, j int) bool { return buckets[i].Value < buckets[j].Value })
+	return buckets, total, nil
+}
+
+// beatsPercentile 计算超过了多少比例的提交（取值严格更大的提交数/总数）
+func beatsPercentile(buckets []dto.HistogramBucket, total int64, value int) float64 {
+	if total == 0 {
+		return 0
+	}
+	var worse int64
+	for _, b := range buckets {
+		if b.Value > value {
+			worse += b.Count
+		}
+	}
+	return math.Round(float64(worse)/float64(total)*10000) / 100
+}
+
+// fillPerformancePercentiles 为通过的提交填充执行时间与内存的百分位
+func fillPerformancePercentiles(resp *dto.SubmissionResponse) error {
+	if resp.Status != "ACCEPTED" {
+		return nil
+	}
+	timeKey, memoryKey := perfKeys(resp.ProblemId, resp.Language)
+
+	exists, err := config.RedisClient.Exists(ctx, timeKey).Result()
+	if err != nil {
+		return err
+	}
+	if exists == 0 {
+		if err := rebuildPerformanceHistogram(resp.ProblemId, resp.Language); err != nil {
+			return err
+		}
+	}
+
+	timeBuckets, timeTotal, err := loadHistogram(timeKey)
+	if err != nil {
+		return err
+	}
+	memoryBuckets, memoryTotal, err := loadHistogram(memoryKey)
+	if err != nil {
+		return err
+	}
+
+	runtime := beatsPercentile(timeBuckets, timeTotal, resp.ExecuteTime)
+	memory := beatsPercentile(memoryBuckets, memoryTotal, memoryBucket(resp.MemoryUsage))
+	resp.RuntimePercentile = &runtime
+	resp.MemoryPercentile = &memory
+	return nil
+}
+
+// GetPerformanceDistribution 获取题目在某语言下通过提交的执行时间与内存分布
+func GetPerformanceDistribution(problemID uint, language string) (*dto.PerformanceDistributionResponse, error) {
+	timeKey, memoryKey := perfKeys(problemID, language)
+
+	exists, err := config.RedisClient.Exists(ctx, timeKey).Result()
+	if err != nil {
+		return nil, err
+	}
+	if exists == 0 {
+		if err := rebuildPerformanceHistogram(problemID, language); err != nil {
+			return nil, err
+		}
+	}
+
+	timeBuckets, total, err := loadHistogram(timeKey)
+	if err != nil {
+		return nil, err
+	}
+	memoryBuckets, _, err := loadHistogram(memoryKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.PerformanceDistributionResponse{
+		ProblemId:      problemID,
+		Language:       language,
+		Total:          total,
+		MemoryBucketKB: memoryBucketKB,
+		Runtime:        timeBuckets,
+		Memory:         memoryBuckets,
+	}, nil
+}
@@ -4,28 +4,54 @@ import (
 	"backend/config"
 	"backend/dto"
 	"backend/entity"
+	"errors"
+	"fmt"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
-// CreateTag 创建标签
+// CreateTag 创建标签，回收站中有同名标签时直接恢复该标签
 func CreateTag(req dto.TagCreateRequest) (*dto.TagResponse, error) {
 	tag := entity.Tag{
 		Name: req.Name,
 	}
 
-	if err := config.DB.Create(&tag).Error; err != nil {
+	restored, err := restoreTagByName(&tag)
+	if err != nil {
 		return nil, err
 	}
+	if !restored {
+		if err := config.DB.Create(&tag).Error; err != nil {
+			return nil, err
+		}
+	}
+	invalidateFeedCache()
+	invalidateTagCache()
 
 	return &dto.TagResponse{
 		ID:        tag.ID,
 		Name:      tag.Name,
+		Version:   tag.Version,
 		CreatedAt: tag.CreatedAt.Format("2006-01-02 15:04:05"),
 		UpdatedAt: tag.UpdatedAt.Format("2006-01-02 15:04:05"),
 	}, nil
 }
 
-// GetAllTags 获取所有标签
+// GetAllTags 获取所有标签（结果缓存在Redis中，标签变化时失效）
 func GetAllTags() ([]dto.TagResponse, error) {
+	var responses []dto.TagResponse
+	key := fmt.Sprintf(TagListKey, cacheVersion(cacheVersionTags))
+	if err := loadWithCache(key, tagListCacheTTL, &responses, func() (interface{}, error) {
+		return queryAllTags()
+	}); err != nil {
+		return nil, err
+	}
+	return responses, nil
+}
+
+// queryAllTags 从数据库查询所有标签
+func queryAllTags() ([]dto.TagResponse, error) {
 	var tags []entity.Tag
 	if err := config.DB.Find(&tags).Error; err != nil {
 		return nil, err
@@ -36,6 +62,7 @@ func GetAllTags() ([]dto.TagResponse, error) {
 		responses = append(responses, dto.TagResponse{
 			ID:        tag.ID,
 			Name:      tag.Name,
+			Version:   tag.Version,
 			CreatedAt: tag.CreatedAt.Format("2006-01-02 15:04:05"),
 			UpdatedAt: tag.UpdatedAt.Format("2006-01-02 15:04:05"),
 		})
@@ -44,27 +71,107 @@ func GetAllTags() ([]dto.TagResponse, error) {
 	return responses, nil
 }
 
-// UpdateTag 更新标签
-func UpdateTag(id uint, req dto.TagUpdateRequest) (*dto.TagResponse, error) {
+// GetTagByID 根据ID获取标签
+func GetTagByID(id uint) (*dto.TagResponse, error) {
 	var tag entity.Tag
 	if err := config.DB.First(&tag, id).Error; err != nil {
 		return nil, err
 	}
 
-	tag.Name = req.Name
-	if err := config.DB.Save(&tag).Error; err != nil {
+	return &dto.TagResponse{
+		ID:        tag.ID,
+		Name:      tag.Name,
+		Version:   tag.Version,
+		CreatedAt: tag.CreatedAt.Format("2006-01-02 15:04:05"),
+		UpdatedAt: tag.UpdatedAt.Format("2006-01-02 15:04:05"),
+	}, nil
+}
+
+// UpdateTag 更新标签，ifMatch为期望的版本号，0表示不校验
+func UpdateTag(id uint, req dto.TagUpdateRequest, ifMatch uint) (*dto.TagResponse, error) {
+	return saveTagChanges(id, ifMatch, &req.Name)
+}
+
+// PatchTag 按JSON Merge Patch修改标签，未提供name时保持不变
+func PatchTag(id uint, req dto.TagPatchRequest, ifMatch uint) (*dto.TagResponse, error) {
+	if err := checkPatchRequired(req.Name, "name"); err != nil {
+		return nil, err
+	}
+
+	var name *string
+	if req.Name.Set {
+		name = &req.Name.Value
+	}
+	return saveTagChanges(id, ifMatch, name)
+}
+
+// saveTagChanges 在事务中校验版本号并修改标签名称，name为nil时只校验版本
+func saveTagChanges(id, ifMatch uint, name *string) (*dto.TagResponse, error) {
+	var tag entity.Tag
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&tag, id).Error; err != nil {
+			return err
+		}
+		if err := checkVersion(ifMatch, tag.Version); err != nil {
+			return err
+		}
+		if name == nil {
+			return nil
+		}
+
+		tag.Name = *name
+		tag.Version++
+		return tx.Save(&tag).Error
+	})
+	if err != nil {
 		return nil, err
 	}
+	if name != nil {
+		invalidateFeedCache()
+		invalidateTagCache()
+	}
 
 	return &dto.TagResponse{
 		ID:        tag.ID,
 		Name:      tag.Name,
+		Version:   tag.Version,
 		CreatedAt: tag.CreatedAt.Format("2006-01-02 15:04:05"),
 		UpdatedAt: tag.UpdatedAt.Format("2006-01-02 15:04:05"),
 	}, nil
 }
 
-// DeleteTag 删除标签
-func DeleteTag(id uint) error {
-	return config.DB.Delete(&entity.Tag{}, id).Error
+// DeleteTag 删除标签，ifMatch为期望的版本号，0表示不校验
+func DeleteTag(id, ifMatch uint) error {
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		var tag entity.Tag
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&tag, id).Error; err != nil {
+			return err
+		}
+		if err := checkVersion(ifMatch, tag.Version); err != nil {
+			return err
+		}
+		return tx.Delete(&tag).Error
+	})
+	if err != nil {
+		return err
+	}
+	invalidateFeedCache()
+	invalidateTagCache()
+	return nil
+}
+
+// restoreTagByName 回收站中有与tag同名的标签时恢复它并写回tag（标签名唯一，已删除的标签仍占用名称）
+func restoreTagByName(tag *entity.Tag) (bool, error) {
+	var deleted entity.Tag
+	err := config.DB.Unscoped().Where("name = ? AND deleted_at IS NOT NULL", tag.Name).First(&deleted).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if err := RestoreTrashItem(TrashTypeTag, deleted.ID); err != nil {
+		return false, err
+	}
+	return true, config.DB.First(tag, deleted.ID).Error
 }
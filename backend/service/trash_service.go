@@ -0,0 +1,321 @@
+package service
+
+import (
+	"backend/config"
+	"backend/dto"
+	"backend/entity"
+	"errors"
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 回收站中的内容类型
+const (
+	TrashTypeArticle = "articles"
+	TrashTypeComment = "comments"
+	TrashTypeTag     = "tags"
+	TrashTypeProblem = "problems"
+)
+
+var (
+	// ErrInvalidTrashType 无效的回收站内容类型
+	ErrInvalidTrashType = errors.New("无效的类型，可选值：articles/comments/tags/problems")
+	// ErrTrashParentDeleted 评论所属的文章已被删除
+	ErrTrashParentDeleted = errors.New("评论所属的文章已被删除，请先恢复文章")
+)
+
+// trashTitleMaxRunes 回收站列表中标题的最大长度
+const trashTitleMaxRunes = 80
+
+// trashRetentionDays 回收站内容的保留天数，到期后永久删除
+func trashRetentionDays() int {
+	return getEnvInt("TRASH_RETENTION_DAYS", 30)
+}
+
+// IsValidTrashType 判断回收站内容类型是否合法
+func IsValidTrashType(itemType string) bool {
+	switch itemType {
+	case TrashTypeArticle, TrashTypeComment, TrashTypeTag, TrashTypeProblem:
+		return true
+	}
+	return false
+}
+
+// trashDeleteTime 软删除使用的时间（精确到毫秒，与数据库中保存的精度一致，便于按删除时间匹配关联数据）
+func trashDeleteTime() time.Time {
+	return time.Now().Truncate(time.Millisecond)
+}
+
+// softDeleteTx 以指定的删除时间软删除记录；同一次删除的关联数据使用相同的删除时间，恢复时据此一并恢复
+func softDeleteTx(tx *gorm.DB, deletedAt time.Time, model interface{}, query string, args ...interface{}) error {
+	return tx.Model(model).Where(query, args...).UpdateColumn("deleted_at", deletedAt).Error
+}
+
+// restoreTx 恢复指定删除时间的记录
+func restoreTx(tx *gorm.DB, deletedAt time.Time, model interface{}, query string, args ...interface{}) error {
+	return tx.Unscoped().Model(model).Where(query, args...).Where("deleted_at = ?", deletedAt).
+		UpdateColumn("deleted_at", nil).Error
+}
+
+// trashQuery 回收站中指定类型内容的查询，以及用作标题的字段
+func trashQuery(itemType string) (*gorm.DB, string) {
+	switch itemType {
+	case TrashTypeArticle:
+		return config.DB.Unscoped().Model(&entity.Article{}).Where("deleted_at IS NOT NULL"), "title"
+	case TrashTypeComment:
+		// 随文章一起删除的评论不单独列出，恢复文章时一并恢复
+		return config.DB.Unscoped().Model(&entity.Comment{}).Where("deleted_at IS NOT NULL").
+			Where("article_id IN (?)", config.DB.Model(&entity.Article{}).Select("id")), "CONCAT(author, '：', content)"
+	case TrashTypeTag:
+		return config.DB.Unscoped().Model(&entity.Tag{}).Where("deleted_at IS NOT NULL"), "name"
+	default:
+		return config.DB.Unscoped().Model(&entity.OJProblem{}).Where("deleted_at IS NOT NULL"), "title"
+	}
+}
+
+// GetTrashItems 分页获取回收站中指定类型的内容，按删除时间倒序
+func GetTrashItems(itemType string, page, pageSize int) (*dto.PageResponse, error) {
+	if !IsValidTrashType(itemType) {
+		return nil, ErrInvalidTrashType
+	}
+
+	query, titleColumn := trashQuery(itemType)
+	query = query.Session(&gorm.Session{})
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, err
+	}
+
+	var rows []struct {
+		ID        uint
+		Title     string
+		DeletedAt time.Time
+	}
+	if err := query.Select("id, " + titleColumn + " AS title, deleted_at").
+		Order("deleted_at desc, id desc").Offset((page - 1) * pageSize).Limit(pageSize).Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	retention := time.Duration(trashRetentionDays()) * 24 * time.Hour
+	list := make([]dto.TrashItem, 0, len(rows))
+	for _, row := range rows {
+		title := []rune(row.Title)
+		if len(title) > trashTitleMaxRunes {
+			title = append(title[:trashTitleMaxRunes], '…')
+		}
+		list = append(list, dto.TrashItem{
+			ID:        row.ID,
+			Type:      itemType,
+			Title:     string(title),
+			DeletedAt: row.DeletedAt.Format("2006-01-02 15:04:05"),
+			PurgeAt:   row.DeletedAt.Add(retention).Format("2006-01-02 15:04:05"),
+		})
+	}
+	return &dto.PageResponse{
+		List:     list,
+		Total:    total,
+		Page:     page,
+		PageSize: pageSize,
+	}, nil
+}
+
+// findTrashedTx 查找回收站中记录的删除时间，记录不存在或未被删除时返回gorm.ErrRecordNotFound
+func findTrashedTx(tx *gorm.DB, model interface{}, id uint) (time.Time, error) {
+	var row struct{ DeletedAt time.Time }
+	result := tx.Unscoped().Model(model).Select("deleted_at").Where("id = ? AND deleted_at IS NOT NULL", id).Scan(&row)
+	if result.Error != nil {
+		return time.Time{}, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return time.Time{}, gorm.ErrRecordNotFound
+	}
+	return row.DeletedAt, nil
+}
+
+// RestoreTrashItem 从回收站恢复内容，以及随其一起删除的关联数据（文章的评论、题目的测试用例和提交记录）
+func RestoreTrashItem(itemType string, id uint) error {
+	if !IsValidTrashType(itemType) {
+		return ErrInvalidTrashType
+	}
+
+	// 恢复评论时记录所属文章，用于清除文章缓存
+	var commentArticleID uint
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		switch itemType {
+		case TrashTypeArticle:
+			deletedAt, err := findTrashedTx(tx, &entity.Article{}, id)
+			if err != nil {
+				return err
+			}
+			if err := restoreTx(tx, deletedAt, &entity.Comment{}, "article_id = ?", id); err != nil {
+				return err
+			}
+			return restoreTx(tx, deletedAt, &entity.Article{}, "id = ?", id)
+		case TrashTypeComment:
+			deletedAt, err := findTrashedTx(tx, &entity.Comment{}, id)
+			if err != nil {
+				return err
+			}
+			var comment entity.Comment
+			if err := tx.Unscoped().Select("id", "article_id").First(&comment, id).Error; err != nil {
+				return err
+			}
+			if err := tx.Select("id").First(&entity.Article{}, comment.ArticleID).Error; errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrTrashParentDeleted
+			} else if err != nil {
+				return err
+			}
+			commentArticleID = comment.ArticleID
+			return restoreTx(tx, deletedAt, &entity.Comment{}, "id = ?", id)
+		case TrashTypeTag:
+			deletedAt, err := findTrashedTx(tx, &entity.Tag{}, id)
+			if err != nil {
+				return err
+			}
+			return restoreTx(tx, deletedAt, &entity.Tag{}, "id = ?", id)
+		default:
+			deletedAt, err := findTrashedTx(tx, &entity.OJProblem{}, id)
+			if err != nil {
+				return err
+			}
+			for _, model := range []interface{}{&entity.OJTestcase{}, &entity.Submission{}} {
+				if err := restoreTx(tx, deletedAt, model, "problem_id = ?", id); err != nil {
+					return err
+				}
+			}
+			return restoreTx(tx, deletedAt, &entity.OJProblem{}, "id = ?", id)
+		}
+	})
+	if err != nil {
+		return err
+	}
+
+	switch itemType {
+	case TrashTypeArticle:
+		var article entity.Article
+		if err := config.DB.First(&article, id).Error; err == nil {
+			syncSearchIndex(article)
+		}
+		onArticlesChanged(id)
+	case TrashTypeComment:
+		invalidateArticleCache(commentArticleID)
+	case TrashTypeTag:
+		invalidateTagCache()
+		invalidateFeedCache()
+	case TrashTypeProblem:
+		invalidateFeedCache()
+		invalidateProblemStats(id)
+	}
+	return nil
+}
+
+// PurgeTrashItem 永久删除回收站中的内容及其关联数据（只能删除已在回收站中的内容）
+func PurgeTrashItem(itemType string, id uint) error {
+	if !IsValidTrashType(itemType) {
+		return ErrInvalidTrashType
+	}
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		switch itemType {
+		case TrashTypeArticle:
+			if _, err := findTrashedTx(tx, &entity.Article{}, id); err != nil {
+				return err
+			}
+			if err := tx.Exec("DELETE FROM article_tags WHERE article_id = ?", id).Error; err != nil {
+				return err
+			}
+			for _, model := range []interface{}{&entity.Comment{}, &entity.ArticleRevision{}, &entity.ArticleSlugRedirect{},
+				&entity.ArticleDailyStat{}, &entity.ArticleReferrerStat{}} {
+				if err := tx.Unscoped().Where("article_id = ?", id).Delete(model).Error; err != nil {
+					return err
+				}
+			}
+			return tx.Unscoped().Delete(&entity.Article{}, id).Error
+		case TrashTypeComment:
+			if _, err := findTrashedTx(tx, &entity.Comment{}, id); err != nil {
+				return err
+			}
+			return tx.Unscoped().Delete(&entity.Comment{}, id).Error
+		case TrashTypeTag:
+			if _, err := findTrashedTx(tx, &entity.Tag{}, id); err != nil {
+				return err
+			}
+			if err := tx.Exec("DELETE FROM article_tags WHERE tag_id = ?", id).Error; err != nil {
+				return err
+			}
+			return tx.Unscoped().Delete(&entity.Tag{}, id).Error
+		default:
+			if _, err := findTrashedTx(tx, &entity.OJProblem{}, id); err != nil {
+				return err
+			}
+			for _, model := range []interface{}{&entity.OJTestcase{}, &entity.Submission{}, &entity.UserProblemStatus{}} {
+				if err := tx.Unscoped().Where("problem_id = ?", id).Delete(model).Error; err != nil {
+					return err
+				}
+			}
+			return tx.Unscoped().Delete(&entity.OJProblem{}, id).Error
+		}
+	})
+	if err != nil {
+		return err
+	}
+
+	switch itemType {
+	case TrashTypeArticle:
+		redisService := &RedisService{}
+		if err := redisService.DeleteArticleData(id, reactionTypes); err != nil {
+			log.Printf("删除文章 %d 的Redis数据失败: %v", id, err)
+		}
+	case TrashTypeProblem:
+		invalidateProblemStats(id)
+	}
+	return nil
+}
+
+// StartTrashPurgeTask 启动回收站清理任务：每天永久删除超过保留期的内容
+func StartTrashPurgeTask() {
+	log.Printf("启动回收站清理任务，内容保留%d天", trashRetentionDays())
+
+	purgeExpiredTrash()
+	ticker := time.NewTicker(24 * time.Hour)
+	defer ticker.Stop()
+	for range ticker.C {
+		purgeExpiredTrash()
+	}
+}
+
+// purgeExpiredTrash 永久删除超过保留期的回收站内容（文章先于评论处理，随文章删除的评论一并清除）
+func purgeExpiredTrash() {
+	cutoff := time.Now().AddDate(0, 0, -trashRetentionDays())
+	models := []struct {
+		itemType string
+		model    interface{}
+	}{
+		{TrashTypeArticle, &entity.Article{}},
+		{TrashTypeComment, &entity.Comment{}},
+		{TrashTypeTag, &entity.Tag{}},
+		{TrashTypeProblem, &entity.OJProblem{}},
+	}
+
+	purged := 0
+	for _, m := range models {
+		var ids []uint
+		if err := config.DB.Unscoped().Model(m.model).Where("deleted_at IS NOT NULL AND deleted_at < ?", cutoff).
+			Pluck("id", &ids).Error; err != nil {
+			log.Printf("查询过期的回收站内容失败: %v", err)
+			continue
+		}
+		for _, id := range ids {
+			if err := PurgeTrashItem(m.itemType, id); err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+				log.Printf("永久删除 %s %d 失败: %v", m.itemType, id, err)
+				continue
+			}
+			purged++
+		}
+	}
+	if purged > 0 {
+		log.Printf("回收站清理完成，永久删除%d项内容", purged)
+	}
+}
@@ -0,0 +1,117 @@
+package service
+
+import (
+	"backend/config"
+	"backend/dto"
+	"backend/entity"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// 用户做题状态
+const (
+	ProblemStatusSolved    = "solved"    // 已通过
+	ProblemStatusAttempted = "attempted" // 尝试过但未通过
+	ProblemStatusUntouched = "untouched" // 未提交过
+	ProblemStatusUnsolved  = "unsolved"  // 仅用于筛选：未通过（含未提交）
+)
+
+// recordUserProblemVerdict 判题结束后更新用户做题状态
+// 使用单条INSERT ... ON DUPLICATE KEY UPDATE，同一用户同一道题的判题结果并发写入时不会丢失
+func recordUserProblemVerdict(submission *entity.Submission) error {
+	if submission.Submitter == "" {
+		return nil
+	}
+	now := time.Now()
+	status := entity.UserProblemStatus{
+		Submitter:    submission.Submitter,
+		ProblemID:    submission.ProblemID,
+		Status:       ProblemStatusAttempted,
+		AttemptCount: 1,
+		LastSubmitAt: now,
+	}
+	if submission.Status == "ACCEPTED" {
+		status.Status = ProblemStatusSolved
+		status.FirstSolvedAt = &now
+	}
+
+	return config.DB.Clauses(clause.OnConflict{
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"attempt_count":   gorm.Expr("attempt_count + 1"),
+			"last_submit_at":  gorm.Expr("VALUES(last_submit_at)"),
+			"updated_at":      gorm.Expr("VALUES(updated_at)"),
+			"first_solved_at": gorm.Expr("COALESCE(first_solved_at, VALUES(first_solved_at))"),
+			// 通过过的题目保持已通过
+			"status": gorm.Expr("IF(status = ? OR VALUES(status) = ?, ?, ?)",
+				ProblemStatusSolved, ProblemStatusSolved, ProblemStatusSolved, ProblemStatusAttempted),
+		}),
+	}).Create(&status).Error
+}
+
+// getUserProblemStatusMap 获取用户所有题目的做题状态
+func getUserProblemStatusMap(submitter string) (map[uint]string, error) {
+	statusMap := make(map[uint]string)
+	if submitter == "" {
+		return statusMap, nil
+	}
+
+	var statuses []entity.UserProblemStatus
+	if err := config.DB.Where("submitter = ?", submitter).Find(&statuses).Error; err != nil {
+		return nil, err
+	}
+	for _, s := range statuses {
+		statusMap[s.ProblemID] = s.Status
+	}
+	return statusMap, nil
+}
+
+// GetUserProblems 获取用户的做题记录，status为空时返回全部
+func GetUserProblems(submitter, status string) ([]dto.UserProblemStatusResponse, error) {
+	var statuses []entity.UserProblemStatus
+	query := config.DB.Where("submitter = ?", submitter)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if err := query.Order("last_submit_at desc").Find(&statuses).Error; err != nil {
+		return nil, err
+	}
+
+	// 批量查询题目标题
+	problemIds := make([]uint, 0, len(statuses))
+	for _, s := range statuses {
+		problemIds = append(problemIds, s.ProblemID)
+	}
+	var problems []entity.OJProblem
+	if len(problemIds) > 0 {
+		if err := config.DB.Select("id", "title", "difficulty").Where("id IN ?", problemIds).Find(&problems).Error; err != nil {
+			return nil, err
+		}
+	}
+	problemMap := make(map[uint]entity.OJProblem, len(problems))
+	for _, p := range problems {
+		problemMap[p.ID] = p
+	}
+
+	responses := make([]dto.UserProblemStatusResponse, 0, len(statuses))
+	for _, s := range statuses {
+		problem, ok := problemMap[s.ProblemID]
+		if !ok {
+			continue // 题目已删除
+		}
+		resp := dto.UserProblemStatusResponse{
+			ProblemId:    s.ProblemID,
+			Title:        problem.Title,
+			Difficulty:   problem.Difficulty,
+			Status:       s.Status,
+			AttemptCount: s.AttemptCount,
+			LastSubmitAt: s.LastSubmitAt.Format("2006-01-02 15:04:05"),
+		}
+		if s.FirstSolvedAt != nil {
+			resp.FirstSolvedAt = s.FirstSolvedAt.Format("2006-01-02 15:04:05")
+		}
+		responses = append(responses, resp)
+	}
+	return responses, nil
+}
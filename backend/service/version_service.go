@@ -0,0 +1,14 @@
+package service
+
+import "errors"
+
+// ErrVersionConflict 内容已被修改，与If-Match中的版本不一致
+var ErrVersionConflict = errors.New("内容已被其他人修改，请基于最新版本合并后重试")
+
+// checkVersion 校验If-Match中的版本号，expected为0表示不校验
+func checkVersion(expected, current uint) error {
+	if expected != 0 && expected != current {
+		return ErrVersionConflict
+	}
+	return nil
+}
@@ -0,0 +1,141 @@
+package utils
+
+import "strings"
+
+// 差异类型
+const (
+	DiffEqual  = "equal"
+	DiffInsert = "insert"
+	DiffDelete = "delete"
+)
+
+// DiffLine 行级差异
+type DiffLine struct {
+	Type    string `json:"type"`              // equal/insert/delete
+	Text    string `json:"text"`              // 行内容
+	OldLine int    `json:"oldLine,omitempty"` // 在旧文本中的行号（从1开始）
+	NewLine int    `json:"newLine,omitempty"` // 在新文本中的行号（从1开始）
+}
+
+// diffMaxEdits 逐行比较的最大编辑距离，超过时中间部分按整体替换输出，避免差异很大的长文本占用过多内存
+const diffMaxEdits = 1000
+
+// DiffLines 使用Myers算法计算两段文本的行级差异
+func DiffLines(oldText, newText string) []DiffLine {
+	a := splitLines(oldText)
+	b := splitLines(newText)
+
+	// 相同的开头和结尾直接输出，只比较中间部分
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	result := make([]DiffLine, 0, len(a)+len(b)-prefix-suffix)
+	for i := 0; i < prefix; i++ {
+		result = append(result, DiffLine{Type: DiffEqual, Text: a[i], OldLine: i + 1, NewLine: i + 1})
+	}
+	result = append(result, myersDiff(a[prefix:len(a)-suffix], b[prefix:len(b)-suffix], prefix)...)
+	for i := suffix; i > 0; i-- {
+		result = append(result, DiffLine{Type: DiffEqual, Text: a[len(a)-i], OldLine: len(a) - i + 1, NewLine: len(b) - i + 1})
+	}
+	return result
+}
+
+// myersDiff 计算两组行的最短编辑脚本，start为它们在原文中之前的行数（用于计算行号）；
+// 编辑距离超过diffMaxEdits时返回整体删除再插入
+func myersDiff(a, b []string, start int) []DiffLine {
+	n, m := len(a), len(b)
+	maxD := n + m
+	if maxD > diffMaxEdits {
+		maxD = diffMaxEdits
+	}
+	offset := maxD + 1
+
+	// 前向搜索；每一步只保存本步可能用到的对角线（k∈[-d-1, d+1]），内存为O(D²)
+	v := make([]int, 2*maxD+3)
+	var trace [][]int
+	for d := 0; d <= maxD; d++ {
+		trace = append(trace, append([]int(nil), v[offset-d-1:offset+d+2]...))
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1] // 向下：插入
+			} else {
+				x = v[offset+k-1] + 1 // 向右：删除
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				return backtrackDiff(a, b, trace, start)
+			}
+		}
+	}
+
+	result := make([]DiffLine, 0, n+m)
+	for i, line := range a {
+		result = append(result, DiffLine{Type: DiffDelete, Text: line, OldLine: start + i + 1})
+	}
+	for i, line := range b {
+		result = append(result, DiffLine{Type: DiffInsert, Text: line, NewLine: start + i + 1})
+	}
+	return result
+}
+
+// backtrackDiff 根据前向搜索的记录回溯得到编辑脚本
+func backtrackDiff(a, b []string, trace [][]int, start int) []DiffLine {
+	var reversed []DiffLine
+	x, y := len(a), len(b)
+	for d := len(trace) - 1; d >= 0; d-- {
+		snapshot := trace[d]
+		v := func(k int) int { return snapshot[k+d+1] }
+		k := x - y
+		var prevK int
+		if k == -d || (k != d && v(k-1) < v(k+1)) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		prevX := v(prevK)
+		prevY := prevX - prevK
+
+		for x > prevX && y > prevY {
+			reversed = append(reversed, DiffLine{Type: DiffEqual, Text: a[x-1], OldLine: start + x, NewLine: start + y})
+			x--
+			y--
+		}
+		if d == 0 {
+			break
+		}
+		if x == prevX {
+			reversed = append(reversed, DiffLine{Type: DiffInsert, Text: b[y-1], NewLine: start + y})
+			y--
+		} else {
+			reversed = append(reversed, DiffLine{Type: DiffDelete, Text: a[x-1], OldLine: start + x})
+			x--
+		}
+	}
+
+	result := make([]DiffLine, len(reversed))
+	for i, line := range reversed {
+		result[len(reversed)-1-i] = line
+	}
+	return result
+}
+
+// splitLines 按行切分文本，空文本返回空切片
+func splitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	return strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+}
@@ -0,0 +1,153 @@
+package utils
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+// applyDiff 根据差异还原旧文本与新文本，并校验行号连续
+func applyDiff(t *testing.T, lines []DiffLine) (oldLines, newLines []string) {
+	t.Helper()
+	for _, line := range lines {
+		switch line.Type {
+		case DiffEqual:
+			oldLines = append(oldLines, line.Text)
+			newLines = append(newLines, line.Text)
+			if line.OldLine != len(oldLines) || line.NewLine != len(newLines) {
+				t.Fatalf("equal行号错误: %+v", line)
+			}
+		case DiffDelete:
+			oldLines = append(oldLines, line.Text)
+			if line.OldLine != len(oldLines) || line.NewLine != 0 {
+				t.Fatalf("delete行号错误: %+v", line)
+			}
+		case DiffInsert:
+			newLines = append(newLines, line.Text)
+			if line.NewLine != len(newLines) || line.OldLine != 0 {
+				t.Fatalf("insert行号错误: %+v", line)
+			}
+		default:
+			t.Fatalf("未知的差异类型: %+v", line)
+		}
+	}
+	return oldLines, newLines
+}
+
+// lcsLength 最长公共子序列长度，用于校验编辑脚本最短
+func lcsLength(a, b []string) int {
+	dp := make([][]int, len(a)+1)
+	for i := range dp {
+		dp[i] = make([]int, len(b)+1)
+	}
+	for i := 1; i <= len(a); i++ {
+		for j := 1; j <= len(b); j++ {
+			if a[i-1] == b[j-1] {
+				dp[i][j] = dp[i-1][j-1] + 1
+			} else if dp[i-1][j] > dp[i][j-1] {
+				dp[i][j] = dp[i-1][j]
+			} else {
+				dp[i][j] = dp[i][j-1]
+			}
+		}
+	}
+	return dp[len(a)][len(b)]
+}
+
+func countEdits(lines []DiffLine) int {
+	edits := 0
+	for _, line := range lines {
+		if line.Type != DiffEqual {
+			edits++
+		}
+	}
+	return edits
+}
+
+func TestDiffLines(t *testing.T) {
+	tests := []struct {
+		name     string
+		old, new string
+		want     []DiffLine
+	}{
+		{"都为空", "", "", []DiffLine{}},
+		{"新增全部", "", "a\nb", []DiffLine{
+			{Type: DiffInsert, Text: "a", NewLine: 1},
+			{Type: DiffInsert, Text: "b", NewLine: 2},
+		}},
+		{"删除全部", "a\n", "", []DiffLine{
+			{Type: DiffDelete, Text: "a", OldLine: 1},
+		}},
+		{"相同", "a\r\nb\n", "a\nb", []DiffLine{
+			{Type: DiffEqual, Text: "a", OldLine: 1, NewLine: 1},
+			{Type: DiffEqual, Text: "b", OldLine: 2, NewLine: 2},
+		}},
+		{"修改中间一行", "a\nb\nc", "a\nx\nc", []DiffLine{
+			{Type: DiffEqual, Text: "a", OldLine: 1, NewLine: 1},
+			{Type: DiffDelete, Text: "b", OldLine: 2},
+			{Type: DiffInsert, Text: "x", NewLine: 2},
+			{Type: DiffEqual, Text: "c", OldLine: 3, NewLine: 3},
+		}},
+		{"开头插入", "b\nc", "a\nb\nc", []DiffLine{
+			{Type: DiffInsert, Text: "a", NewLine: 1},
+			{Type: DiffEqual, Text: "b", OldLine: 1, NewLine: 2},
+			{Type: DiffEqual, Text: "c", OldLine: 2, NewLine: 3},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := DiffLines(tt.old, tt.new)
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("DiffLines(%q, %q) = %+v, want %+v", tt.old, tt.new, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDiffLinesRandom(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	randomLines := func() []string {
+		lines := make([]string, rng.Intn(30))
+		for i := range lines {
+			lines[i] = string(rune('a' + rng.Intn(4)))
+		}
+		return lines
+	}
+	for i := 0; i < 500; i++ {
+		a, b := randomLines(), randomLines()
+		lines := DiffLines(strings.Join(a, "\n"), strings.Join(b, "\n"))
+		gotOld, gotNew := applyDiff(t, lines)
+		if strings.Join(gotOld, "\n") != strings.Join(a, "\n") || strings.Join(gotNew, "\n") != strings.Join(b, "\n") {
+			t.Fatalf("差异无法还原原文: %v -> %v", a, b)
+		}
+		if want := len(a) + len(b) - 2*lcsLength(a, b); countEdits(lines) != want {
+			t.Fatalf("编辑次数 %d 不是最少的 %d: %v -> %v", countEdits(lines), want, a, b)
+		}
+	}
+}
+
+func TestDiffLinesLargeDifference(t *testing.T) {
+	// 差异超过上限时不再逐行比较，但结果仍能还原两段文本
+	var a, b []string
+	a = append(a, "same-head")
+	b = append(b, "same-head")
+	for i := 0; i < 3*diffMaxEdits; i++ {
+		a = append(a, fmt.Sprintf("old-%d", i))
+		b = append(b, fmt.Sprintf("new-%d", i))
+	}
+	a = append(a, "same-tail")
+	b = append(b, "same-tail")
+
+	lines := DiffLines(strings.Join(a, "\n"), strings.Join(b, "\n"))
+	gotOld, gotNew := applyDiff(t, lines)
+	if len(gotOld) != len(a) || len(gotNew) != len(b) {
+		t.Fatalf("还原的行数错误: %d/%d, want %d/%d", len(gotOld), len(gotNew), len(a), len(b))
+	}
+	if lines[0].Type != DiffEqual || lines[len(lines)-1].Type != DiffEqual {
+		t.Errorf("相同的开头和结尾应保留为equal")
+	}
+	if countEdits(lines) != 6*diffMaxEdits {
+		t.Errorf("编辑次数 = %d, want %d", countEdits(lines), 6*diffMaxEdits)
+	}
+}
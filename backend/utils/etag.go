@@ -0,0 +1,71 @@
+package utils
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// VersionETag 根据资源版本号生成强ETag，如 "v3"
+func VersionETag(version uint) string {
+	return fmt.Sprintf(`"v%d"`, version)
+}
+
+// IfMatch 解析后的If-Match请求头
+type IfMatch struct {
+	Any      bool   // 未提供或为*，不校验版本
+	Versions []uint // 列表中的版本号；If-Match使用强比较，弱ETag和非版本格式的ETag不会匹配，不在其中
+}
+
+// Matches 判断资源的当前版本是否满足If-Match
+func (m IfMatch) Matches(version uint) bool {
+	if m.Any {
+		return true
+	}
+	for _, v := range m.Versions {
+		if v == version {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseIfMatch 解析If-Match请求头（RFC 7232）：*或逗号分隔的ETag列表，格式错误时返回ok=false
+func ParseIfMatch(header string) (match IfMatch, ok bool) {
+	rest := strings.TrimSpace(header)
+	if rest == "" || rest == "*" {
+		return IfMatch{Any: true}, true
+	}
+
+	for rest != "" {
+		// 允许空的列表项，如 "v1", , "v2"
+		if rest[0] == ',' {
+			rest = strings.TrimSpace(rest[1:])
+			continue
+		}
+		weak := strings.HasPrefix(rest, "W/")
+		if weak {
+			rest = rest[2:]
+		}
+		if !strings.HasPrefix(rest, `"`) {
+			return IfMatch{}, false
+		}
+		end := strings.IndexByte(rest[1:], '"')
+		if end < 0 {
+			return IfMatch{}, false
+		}
+		tag := rest[1 : end+1]
+		rest = strings.TrimSpace(rest[end+2:])
+		if rest != "" && rest[0] != ',' {
+			return IfMatch{}, false
+		}
+
+		if weak || !strings.HasPrefix(tag, "v") {
+			continue
+		}
+		if n, err := strconv.ParseUint(tag[1:], 10, 32); err == nil && n > 0 {
+			match.Versions = append(match.Versions, uint(n))
+		}
+	}
+	return match, true
+}
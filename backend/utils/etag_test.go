@@ -0,0 +1,64 @@
+package utils
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestVersionETag(t *testing.T) {
+	if got := VersionETag(3); got != `"v3"` {
+		t.Errorf("VersionETag(3) = %s", got)
+	}
+}
+
+func TestParseIfMatch(t *testing.T) {
+	tests := []struct {
+		header string
+		want   IfMatch
+		ok     bool
+	}{
+		{"", IfMatch{Any: true}, true},
+		{"  *  ", IfMatch{Any: true}, true},
+		{`"v3"`, IfMatch{Versions: []uint{3}}, true},
+		{` "v3" `, IfMatch{Versions: []uint{3}}, true},
+		{`"v1", "v2"`, IfMatch{Versions: []uint{1, 2}}, true},
+		{`"v1",,"v2",`, IfMatch{Versions: []uint{1, 2}}, true},
+		{`"a,b", "v2"`, IfMatch{Versions: []uint{2}}, true},
+		// 强比较：弱ETag与非版本格式的ETag不会匹配任何版本
+		{`W/"v3"`, IfMatch{}, true},
+		{`W/"v3", "v4"`, IfMatch{Versions: []uint{4}}, true},
+		{`"abc"`, IfMatch{}, true},
+		{`"v0"`, IfMatch{}, true},
+		{`"v-1"`, IfMatch{}, true},
+		// 格式错误
+		{`v3`, IfMatch{}, false},
+		{`"v3`, IfMatch{}, false},
+		{`"v3" "v4"`, IfMatch{}, false},
+		{`W/v3`, IfMatch{}, false},
+	}
+	for _, tt := range tests {
+		got, ok := ParseIfMatch(tt.header)
+		if ok != tt.ok || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParseIfMatch(%q) = %+v, %v, want %+v, %v", tt.header, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestIfMatchMatches(t *testing.T) {
+	tests := []struct {
+		match   IfMatch
+		version uint
+		want    bool
+	}{
+		{IfMatch{Any: true}, 7, true},
+		{IfMatch{Versions: []uint{7}}, 7, true},
+		{IfMatch{Versions: []uint{6, 7}}, 7, true},
+		{IfMatch{Versions: []uint{6}}, 7, false},
+		{IfMatch{}, 7, false},
+	}
+	for _, tt := range tests {
+		if got := tt.match.Matches(tt.version); got != tt.want {
+			t.Errorf("%+v.Matches(%d) = %v, want %v", tt.match, tt.version, got, tt.want)
+		}
+	}
+}
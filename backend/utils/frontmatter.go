@@ -0,0 +1,125 @@
+package utils
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// FrontMatter Markdown文件头部的YAML元数据（兼容Hexo）
+type FrontMatter struct {
+	Title       string     `yaml:"title"`
+	Slug        string     `yaml:"slug,omitempty"`
+	Date        string     `yaml:"date,omitempty"`    // 发布时间
+	Updated     string     `yaml:"updated,omitempty"` // 最后更新时间（仅导出时写入）
+	Tags        StringList `yaml:"tags,omitempty"`
+	Summary     string     `yaml:"summary,omitempty"`
+	Description string     `yaml:"description,omitempty"` // Hexo的摘要字段，summary为空时使用
+	Cover       string     `yaml:"cover,omitempty"`
+	Status      string     `yaml:"status,omitempty"` // 文章状态，为空表示已发布
+}
+
+// StringList 字符串列表，兼容单个字符串的写法（如 tags: Go）
+type StringList []string
+
+// UnmarshalYAML 解析列表或单个字符串
+func (l *StringList) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		if node.Value == "" {
+			*l = nil
+		} else {
+			*l = StringList{node.Value}
+		}
+		return nil
+	}
+	var items []string
+	if err := node.Decode(&items); err != nil {
+		return err
+	}
+	*l = items
+	return nil
+}
+
+// FrontMatterTimeLayout 导出时使用的时间格式（与Hexo一致）
+const FrontMatterTimeLayout = "2006-01-02 15:04:05"
+
+// frontMatterTimeLayouts 导入时支持的时间格式
+var frontMatterTimeLayouts = []string{
+	FrontMatterTimeLayout,
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04",
+	"2006/01/02 15:04:05",
+	"2006/01/02",
+	"2006-01-02",
+}
+
+// ParseFrontMatterTime 解析元数据中的时间，未带时区的按本地时间处理
+func ParseFrontMatterTime(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	for _, layout := range frontMatterTimeLayouts {
+		if t, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, errors.New("无法识别的时间格式: " + value)
+}
+
+// ParseFrontMatter 拆分Markdown文件的YAML元数据与正文，没有元数据时返回空的元数据与原文
+func ParseFrontMatter(content string) (*FrontMatter, string, error) {
+	content = strings.TrimPrefix(content, "\ufeff") // UTF-8 BOM
+	content = strings.ReplaceAll(content, "\r\n", "\n")
+
+	fm := &FrontMatter{}
+	if !strings.HasPrefix(content, "---\n") {
+		return fm, content, nil
+	}
+	rest := content[len("---\n"):]
+	var header, body string
+	if strings.HasPrefix(rest, "---") {
+		// 空的元数据
+		body = rest[len("---"):]
+	} else {
+		end := strings.Index(rest, "\n---")
+		if end < 0 {
+			return nil, "", errors.New("元数据缺少结束标记 ---")
+		}
+		header = rest[:end+1]
+		body = rest[end+len("\n---"):]
+	}
+	// 结束标记所在行的其余部分与紧随其后的空行不属于正文
+	if i := strings.IndexByte(body, '\n'); i >= 0 {
+		body = body[i+1:]
+	} else {
+		body = ""
+	}
+	body = strings.TrimLeft(body, "\n")
+
+	if err := yaml.Unmarshal([]byte(header), fm); err != nil {
+		return nil, "", err
+	}
+	return fm, body, nil
+}
+
+// FormatFrontMatter 生成带YAML元数据的Markdown文件内容
+func FormatFrontMatter(fm *FrontMatter, body string) (string, error) {
+	var buf bytes.Buffer
+	buf.WriteString("---\n")
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(fm); err != nil {
+		return "", err
+	}
+	if err := encoder.Close(); err != nil {
+		return "", err
+	}
+	buf.WriteString("---\n\n")
+	buf.WriteString(body)
+	if !strings.HasSuffix(body, "\n") {
+		buf.WriteString("\n")
+	}
+	return buf.String(), nil
+}
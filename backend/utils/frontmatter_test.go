@@ -0,0 +1,109 @@
+package utils
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseFrontMatter(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    *FrontMatter
+		body    string
+		wantErr bool
+	}{
+		{"没有元数据", "# 标题\n正文", &FrontMatter{}, "# 标题\n正文", false},
+		{"完整元数据", "---\ntitle: Go入门\nslug: go-intro\ndate: 2024-01-02 03:04:05\ntags:\n  - Go\n  - 后端\nsummary: 摘要\ncover: images/a.png\nstatus: draft\n---\n\n正文\n",
+			&FrontMatter{Title: "Go入门", Slug: "go-intro", Date: "2024-01-02 03:04:05", Tags: StringList{"Go", "后端"},
+				Summary: "摘要", Cover: "images/a.png", Status: "draft"}, "正文\n", false},
+		{"单个标签", "---\ntitle: a\ntags: Go\n---\nbody", &FrontMatter{Title: "a", Tags: StringList{"Go"}}, "body", false},
+		{"空标签", "---\ntitle: a\ntags:\n---\nbody", &FrontMatter{Title: "a"}, "body", false},
+		{"行内列表", "---\ntags: [Go, Rust]\n---\nbody", &FrontMatter{Tags: StringList{"Go", "Rust"}}, "body", false},
+		{"Hexo摘要", "---\ntitle: a\ndescription: 描述\n---\nbody", &FrontMatter{Title: "a", Description: "描述"}, "body", false},
+		{"空的元数据", "---\n---\nbody", &FrontMatter{}, "body", false},
+		{"BOM与CRLF", "\ufeff---\r\ntitle: a\r\n---\r\n\r\nbody\r\n", &FrontMatter{Title: "a"}, "body\n", false},
+		{"正文中的分隔线", "---\ntitle: a\n---\n上文\n\n---\n\n下文", &FrontMatter{Title: "a"}, "上文\n\n---\n\n下文", false},
+		{"只有元数据", "---\ntitle: a\n---", &FrontMatter{Title: "a"}, "", false},
+		{"缺少结束标记", "---\ntitle: a\nbody", nil, "", true},
+		{"YAML格式错误", "---\ntitle: [a\n---\nbody", nil, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fm, body, err := ParseFrontMatter(tt.content)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseFrontMatter() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if !reflect.DeepEqual(fm, tt.want) {
+				t.Errorf("元数据 = %+v, want %+v", fm, tt.want)
+			}
+			if body != tt.body {
+				t.Errorf("正文 = %q, want %q", body, tt.body)
+			}
+		})
+	}
+}
+
+func TestFormatFrontMatterRoundTrip(t *testing.T) {
+	tests := []struct {
+		name string
+		fm   *FrontMatter
+		body string
+		want string
+	}{
+		{"补齐结尾换行", &FrontMatter{Title: "a"}, "正文", "正文\n"},
+		{"保留原有换行", &FrontMatter{Title: "a: b", Tags: StringList{"Go", "- x"}}, "正文\n\n---\n", "正文\n\n---\n"},
+		{"全部字段", &FrontMatter{Title: "t", Slug: "s", Date: "2024-01-02 03:04:05", Updated: "2024-01-03 00:00:00",
+			Tags: StringList{"Go"}, Summary: "多行\n摘要", Cover: "c.png", Status: "archived"}, "body\n", "body\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			content, err := FormatFrontMatter(tt.fm, tt.body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			fm, body, err := ParseFrontMatter(content)
+			if err != nil {
+				t.Fatalf("解析导出的内容失败: %v\n%s", err, content)
+			}
+			if !reflect.DeepEqual(fm, tt.fm) {
+				t.Errorf("元数据 = %+v, want %+v", fm, tt.fm)
+			}
+			if body != tt.want {
+				t.Errorf("正文 = %q, want %q", body, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseFrontMatterTime(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    time.Time
+		wantErr bool
+	}{
+		{"2024-01-02 03:04:05", time.Date(2024, 1, 2, 3, 4, 5, 0, time.Local), false},
+		{" 2024-01-02 03:04 ", time.Date(2024, 1, 2, 3, 4, 0, 0, time.Local), false},
+		{"2024-01-02T03:04:05", time.Date(2024, 1, 2, 3, 4, 5, 0, time.Local), false},
+		{"2024-01-02T03:04:05Z", time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC), false},
+		{"2024/01/02 03:04:05", time.Date(2024, 1, 2, 3, 4, 5, 0, time.Local), false},
+		{"2024/01/02", time.Date(2024, 1, 2, 0, 0, 0, 0, time.Local), false},
+		{"2024-01-02", time.Date(2024, 1, 2, 0, 0, 0, 0, time.Local), false},
+		{"01/02/2024", time.Time{}, true},
+		{"", time.Time{}, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseFrontMatterTime(tt.value)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseFrontMatterTime(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			continue
+		}
+		if !got.Equal(tt.want) {
+			t.Errorf("ParseFrontMatterTime(%q) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,17 @@
+package utils
+
+import "strings"
+
+// allowedImageExts 允许上传和导入的图片扩展名（不含svg：图片与站点同源，svg中的脚本会被执行）
+var allowedImageExts = []string{".jpg", ".jpeg", ".png", ".gif", ".bmp", ".webp"}
+
+// IsAllowedImageExt 判断文件扩展名（含点，不区分大小写）是否为允许的图片类型
+func IsAllowedImageExt(ext string) bool {
+	ext = strings.ToLower(ext)
+	for _, allowed := range allowedImageExts {
+		if ext == allowed {
+			return true
+		}
+	}
+	return false
+}
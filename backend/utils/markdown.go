@@ -0,0 +1,103 @@
+package utils
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+var (
+	mdCodeFenceRe  = regexp.MustCompile("^(`{3,}|~{3,})")
+	mdImageRe      = regexp.MustCompile(`!\[([^\]]*)\]\([^)]*\)`)
+	mdLinkRe       = regexp.MustCompile(`\[([^\]]*)\]\([^)]*\)`)
+	mdHTMLTagRe    = regexp.MustCompile(`<[^>]+>`)
+	mdHeadingRe    = regexp.MustCompile(`(?m)^\s{0,3}#{1,6}\s*`)
+	mdBlockquoteRe = regexp.MustCompile(`(?m)^\s{0,3}>\s?`)
+	mdListRe       = regexp.MustCompile(`(?m)^\s*([-*+]|\d+\.)\s+`)
+	mdRuleRe       = regexp.MustCompile(`(?m)^\s*([-*_]\s*){3,}$`)
+	mdTableSepRe   = regexp.MustCompile(`(?m)^\s*\|?(\s*:?-+:?\s*\|)+\s*:?-*:?\s*$`)
+	mdEmphasisRe   = regexp.MustCompile(`(\*\*|__|\*|~~|` + "`" + `)`)
+	mdMathRe       = regexp.MustCompile(`\$\$[^$]+\$\$|\$[^\s$](?:[^$\n]*[^\s$])?\$`)
+	mdSpaceRe      = regexp.MustCompile(`\s+`)
+	mdImageURLRe   = regexp.MustCompile(`!\[[^\]]*\]\(\s*<?([^)\s>]+)>?(?:\s+["'(][^)]*)?\)|<img[^>]+src\s*=\s*["']([^"']+)["']`)
+)
+
+// stripCodeBlocks 去掉围栏代码块（含代码内容），未闭合的代码块一直延续到文末
+func stripCodeBlocks(markdown string) string {
+	lines := strings.Split(markdown, "\n")
+	kept := lines[:0]
+	fence := ""
+	for _, line := range lines {
+		trimmed := strings.TrimLeft(line, " \t")
+		if fence == "" {
+			if fence = mdCodeFenceRe.FindString(trimmed); fence == "" {
+				kept = append(kept, line)
+			}
+			continue
+		}
+		// 结束围栏使用相同的字符，长度不少于开始围栏，且后面只能有空白
+		if closing := mdCodeFenceRe.FindString(trimmed); closing != "" && closing[0] == fence[0] &&
+			len(closing) >= len(fence) && strings.TrimSpace(trimmed[len(closing):]) == "" {
+			fence = ""
+		}
+	}
+	return strings.Join(kept, "\n")
+}
+
+// StripMarkdown 去除Markdown语法，返回纯文本（连续空白合并为一个空格），代码块和公式不计入
+func StripMarkdown(markdown string) string {
+	text := stripCodeBlocks(markdown)
+	text = mdImageRe.ReplaceAllString(text, "")
+	text = mdLinkRe.ReplaceAllString(text, "$1")
+	text = mdHTMLTagRe.ReplaceAllString(text, "")
+	text = mdMathRe.ReplaceAllString(text, "")
+	text = mdTableSepRe.ReplaceAllString(text, "")
+	text = mdHeadingRe.ReplaceAllString(text, "")
+	text = mdBlockquoteRe.ReplaceAllString(text, "")
+	text = mdRuleRe.ReplaceAllString(text, "")
+	text = mdListRe.ReplaceAllString(text, "")
+	text = mdEmphasisRe.ReplaceAllString(text, "")
+	text = strings.ReplaceAll(text, "|", " ")
+	return strings.TrimSpace(mdSpaceRe.ReplaceAllString(text, " "))
+}
+
+// Summarize 由Markdown生成纯文本摘要，超过maxRunes个字符时截断并追加省略号
+func Summarize(markdown string, maxRunes int) string {
+	runes := []rune(StripMarkdown(markdown))
+	if len(runes) <= maxRunes {
+		return string(runes)
+	}
+	return strings.TrimSpace(string(runes[:maxRunes])) + "…"
+}
+
+// CountWords 统计字数：每个汉字（及日韩文字）计为一个字，其余按连续字母数字计为一个单词
+func CountWords(text string) (cjk, words int) {
+	inWord := false
+	for _, r := range text {
+		switch {
+		case unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul):
+			cjk++
+			inWord = false
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			if !inWord {
+				words++
+			}
+			inWord = true
+		default:
+			inWord = false
+		}
+	}
+	return cjk, words
+}
+
+// FirstImageURL 返回Markdown中第一张图片的地址（支持 ![]() 与 <img> 两种写法），没有则返回空字符串
+func FirstImageURL(markdown string) string {
+	m := mdImageURLRe.FindStringSubmatch(markdown)
+	if m == nil {
+		return ""
+	}
+	if m[1] != "" {
+		return m[1]
+	}
+	return m[2]
+}
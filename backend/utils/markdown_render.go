@@ -0,0 +1,231 @@
+package utils
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+)
+
+// TocItem 目录项
+type TocItem struct {
+	Level    int        `json:"level"`              // 标题级别 1-6
+	Text     string     `json:"text"`               // 标题文本
+	Anchor   string     `json:"anchor"`             // 锚点（标题元素的id）
+	Children []*TocItem `json:"children,omitempty"` // 下级标题
+}
+
+// markdownRenderer 渲染器：GFM（表格、删除线、任务列表、自动链接）+ 数学公式占位
+// 未开启 html.WithUnsafe，原始HTML会被忽略，javascript: 等危险链接会被过滤
+var markdownRenderer = goldmark.New(
+	goldmark.WithExtensions(extension.GFM, mathExtension{}),
+	goldmark.WithParserOptions(parser.WithAutoHeadingID()),
+)
+
+// RenderMarkdown 将Markdown渲染为安全的HTML，并按标题生成目录
+func RenderMarkdown(source string) (string, []*TocItem, error) {
+	src := []byte(source)
+	ctx := parser.NewContext(parser.WithIDs(newHeadingIDs()))
+	doc := markdownRenderer.Parser().Parse(text.NewReader(src), parser.WithContext(ctx))
+
+	var buf bytes.Buffer
+	if err := markdownRenderer.Renderer().Render(&buf, src, doc); err != nil {
+		return "", nil, err
+	}
+	return buf.String(), buildToc(doc, src), nil
+}
+
+// buildToc 遍历文档中的标题，按级别构造嵌套目录
+func buildToc(doc ast.Node, source []byte) []*TocItem {
+	var roots []*TocItem
+	var stack []*TocItem
+	_ = ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		heading, ok := n.(*ast.Heading)
+		if !ok || !entering {
+			return ast.WalkContinue, nil
+		}
+		item := &TocItem{Level: heading.Level, Text: string(heading.Text(source))}
+		if id, ok := heading.AttributeString("id"); ok {
+			if b, ok := id.([]byte); ok {
+				item.Anchor = string(b)
+			}
+		}
+
+		// 找到最近的更高级标题作为父节点
+		for len(stack) > 0 && stack[len(stack)-1].Level >= item.Level {
+			stack = stack[:len(stack)-1]
+		}
+		if len(stack) == 0 {
+			roots = append(roots, item)
+		} else {
+			parent := stack[len(stack)-1]
+			parent.Children = append(parent.Children, item)
+		}
+		stack = append(stack, item)
+		return ast.WalkSkipChildren, nil
+	})
+	return roots
+}
+
+// headingIDs 标题锚点生成器：保留中文等Unicode字母，重复的锚点追加序号，保证同一内容多次渲染结果一致
+type headingIDs struct {
+	used map[string]bool
+}
+
+func newHeadingIDs() *headingIDs {
+	return &headingIDs{used: make(map[string]bool)}
+}
+
+// Generate 根据标题文本生成锚点
+func (s *headingIDs) Generate(value []byte, kind ast.NodeKind) []byte {
+	var sb strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(string(value)) {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			if dash && sb.Len() > 0 {
+				sb.WriteByte('-')
+			}
+			dash = false
+			sb.WriteRune(r)
+		case unicode.IsSpace(r) || r == '-' || r == '_':
+			dash = true
+		}
+	}
+
+	base := sb.String()
+	if base == "" {
+		base = "section"
+	}
+	id := base
+	for i := 1; s.used[id]; i++ {
+		id = base + "-" + strconv.Itoa(i)
+	}
+	s.used[id] = true
+	return []byte(id)
+}
+
+// Put 记录已使用的锚点
+func (s *headingIDs) Put(value []byte) {
+	s.used[string(value)] = true
+}
+
+// kindMath 数学公式节点类型
+var kindMath = ast.NewNodeKind("Math")
+
+// mathNode 数学公式节点，$...$ 为行内公式，$$...$$ 为块级公式
+type mathNode struct {
+	ast.BaseInline
+	Display bool
+	Literal []byte
+}
+
+// Kind 节点类型
+func (n *mathNode) Kind() ast.NodeKind {
+	return kindMath
+}
+
+// Dump 调试输出
+func (n *mathNode) Dump(source []byte, level int) {
+	ast.DumpHelper(n, source, level, map[string]string{"Literal": string(n.Literal)}, nil)
+}
+
+// mathParser 解析 $...$ 与 $$...$$，公式内容不做Markdown转义处理
+type mathParser struct{}
+
+// Trigger 触发字符
+func (p *mathParser) Trigger() []byte {
+	return []byte{'$'}
+}
+
+// Parse 解析数学公式，找不到闭合符号时按普通文本处理
+func (p *mathParser) Parse(parent ast.Node, block text.Reader, pc parser.Context) ast.Node {
+	line, _ := block.PeekLine()
+	opener := 0
+	for ; opener < len(line) && line[opener] == '$'; opener++ {
+	}
+	if opener > 2 {
+		return nil
+	}
+	// 行内公式 $ 后不能紧跟空白，避免把 "$5 和 $10" 识别为公式
+	if opener == 1 && (len(line) < 2 || util.IsSpace(line[1])) {
+		return nil
+	}
+
+	l, pos := block.Position()
+	block.Advance(opener)
+	var literal []byte
+	for {
+		line, _ := block.PeekLine()
+		if line == nil {
+			block.SetPosition(l, pos)
+			return nil
+		}
+		for i := 0; i < len(line); i++ {
+			if line[i] == '\\' {
+				i++
+				continue
+			}
+			if line[i] != '$' {
+				continue
+			}
+			j := i
+			for ; j < len(line) && line[j] == '$'; j++ {
+			}
+			if j-i != opener {
+				i = j - 1
+				continue
+			}
+			content := append(append([]byte(nil), literal...), line[:i]...)
+			if opener == 1 && (len(content) == 0 || util.IsSpace(content[len(content)-1]) ||
+				(j < len(line) && line[j] >= '0' && line[j] <= '9')) {
+				i = j - 1
+				continue
+			}
+			block.Advance(j)
+			return &mathNode{Display: opener == 2, Literal: bytes.TrimSpace(content)}
+		}
+		literal = append(literal, line...)
+		block.AdvanceLine()
+	}
+}
+
+// mathHTMLRenderer 将公式渲染为占位元素，由前端（如KaTeX）按class渲染
+type mathHTMLRenderer struct{}
+
+// RegisterFuncs 注册渲染函数
+func (r *mathHTMLRenderer) RegisterFuncs(reg renderer.NodeRendererFuncRegisterer) {
+	reg.Register(kindMath, r.renderMath)
+}
+
+func (r *mathHTMLRenderer) renderMath(w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	if !entering {
+		return ast.WalkContinue, nil
+	}
+	n := node.(*mathNode)
+	class := "math math-inline"
+	if n.Display {
+		class = "math math-display"
+	}
+	_, _ = w.WriteString(`<span class="` + class + `">`)
+	_, _ = w.Write(util.EscapeHTML(n.Literal))
+	_, _ = w.WriteString("</span>")
+	return ast.WalkSkipChildren, nil
+}
+
+// mathExtension 数学公式扩展
+type mathExtension struct{}
+
+// Extend 注册解析器与渲染器
+func (e mathExtension) Extend(m goldmark.Markdown) {
+	m.Parser().AddOptions(parser.WithInlineParsers(util.Prioritized(&mathParser{}, 500)))
+	m.Renderer().AddOptions(renderer.WithNodeRenderers(util.Prioritized(&mathHTMLRenderer{}, 500)))
+}
@@ -0,0 +1,39 @@
+package utils
+
+import "testing"
+
+func TestStripMarkdown(t *testing.T) {
+	tests := []struct {
+		name     string
+		markdown string
+		want     string
+	}{
+		{"标题与强调", "# 标题\n\n这是**加粗**和*斜体*、~~删除~~", "标题 这是加粗和斜体、删除"},
+		{"链接与图片", "看[文档](https://a.com)![图](x.png)", "看文档"},
+		{"列表与引用", "- 一\n1. 二\n> 三", "一 二 三"},
+		{"表格", "| a | b |\n| --- | :-: |\n| 1 | 2 |", "a b 1 2"},
+		{"行内代码保留内容", "调用`Run()`即可", "调用Run()即可"},
+		{"代码块去掉内容", "前\n```go\nfunc main() {}\n```\n后", "前 后"},
+		{"波浪线围栏", "前\n~~~\ncode\n~~~\n后", "前 后"},
+		{"较长的开始围栏", "前\n````\n```\ninner\n```\n````\n后", "前 后"},
+		{"不同字符不能闭合", "前\n```\n~~~\ncode\n```\n后", "前 后"},
+		{"带信息串的行不能闭合", "前\n```\n```js\ncode\n```\n后", "前 后"},
+		{"缩进的代码块", "- 项\n  ```\n  code\n  ```\n后", "项 后"},
+		{"未闭合延续到文末", "前\n```\ncode", "前"},
+		{"CRLF换行", "前\r\n```\r\ncode\r\n```\r\n后", "前 后"},
+		{"行内公式", "质能方程$E=mc^2$成立", "质能方程成立"},
+		{"单字符公式", "设$x$为整数", "设为整数"},
+		{"块级公式", "前\n$$\n\\sum_{i=1}^n i\n$$\n后", "前 后"},
+		{"金额不是公式", "价格从$5 and $10不等", "价格从$5 and $10不等"},
+		{"美元符号后为空格", "a $ b $ c", "a $ b $ c"},
+		{"HTML标签", "<p>段落</p><br/>", "段落"},
+		{"空白合并", "  a \n\n\t b  ", "a b"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := StripMarkdown(tt.markdown); got != tt.want {
+				t.Errorf("StripMarkdown(%q) = %q, want %q", tt.markdown, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,56 @@
+package utils
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/mozillazg/go-pinyin"
+)
+
+// SlugMaxLength slug最大长度
+const SlugMaxLength = 80
+
+var (
+	slugRe     = regexp.MustCompile(`^[a-z0-9]+(?:-[a-z0-9]+)*$`)
+	slugDashRe = regexp.MustCompile(`-+`)
+	pinyinArgs = pinyin.NewArgs()
+)
+
+// Slugify 由标题生成slug：汉字转为不带声调的拼音，英文转小写，其余字符作为分隔符
+func Slugify(title string) string {
+	var parts []string
+	var word strings.Builder
+	flush := func() {
+		if word.Len() > 0 {
+			parts = append(parts, word.String())
+			word.Reset()
+		}
+	}
+
+	for _, r := range strings.ToLower(title) {
+		switch {
+		case unicode.Is(unicode.Han, r):
+			flush()
+			if py := pinyin.SinglePinyin(r, pinyinArgs); len(py) > 0 {
+				parts = append(parts, py[0])
+			}
+		case r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)):
+			word.WriteRune(r)
+		default:
+			flush()
+		}
+	}
+	flush()
+
+	slug := strings.Trim(slugDashRe.ReplaceAllString(strings.Join(parts, "-"), "-"), "-")
+	if len(slug) > SlugMaxLength {
+		slug = strings.Trim(slug[:SlugMaxLength], "-")
+	}
+	return slug
+}
+
+// IsValidSlug 判断slug是否合法：小写字母、数字，以单个连字符分隔
+func IsValidSlug(slug string) bool {
+	return len(slug) <= SlugMaxLength && slugRe.MatchString(slug)
+}
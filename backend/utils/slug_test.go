@@ -0,0 +1,60 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSlugify(t *testing.T) {
+	tests := []struct {
+		title string
+		want  string
+	}{
+		{"Go 语言入门", "go-yu-yan-ru-men"},
+		{"Hello, World!", "hello-world"},
+		{"  --Docker__Compose--  ", "docker-compose"},
+		{"Vue3与React18", "vue3-yu-react18"},
+		{"C++ & Rust", "c-rust"},
+		{"Ünïcödé café", "n-c-d-caf"},
+		{"🎉🎉", ""},
+		{"", ""},
+		{strings.Repeat("ab-", 40), strings.TrimSuffix(strings.Repeat("ab-", 27), "-")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.title, func(t *testing.T) {
+			got := Slugify(tt.title)
+			if got != tt.want {
+				t.Errorf("Slugify(%q) = %q, want %q", tt.title, got, tt.want)
+			}
+			if got != "" && !IsValidSlug(got) {
+				t.Errorf("Slugify(%q) = %q 不是合法的slug", tt.title, got)
+			}
+		})
+	}
+}
+
+func TestIsValidSlug(t *testing.T) {
+	tests := []struct {
+		slug string
+		want bool
+	}{
+		{"go-yu-yan", true},
+		{"a", true},
+		{"v2-release-2024", true},
+		{strings.Repeat("a", SlugMaxLength), true},
+		{strings.Repeat("a", SlugMaxLength+1), false},
+		{"", false},
+		{"Go-Lang", false},
+		{"-go", false},
+		{"go-", false},
+		{"go--lang", false},
+		{"go_lang", false},
+		{"中文", false},
+		{"123", true},
+	}
+	for _, tt := range tests {
+		if got := IsValidSlug(tt.slug); got != tt.want {
+			t.Errorf("IsValidSlug(%q) = %v, want %v", tt.slug, got, tt.want)
+		}
+	}
+}
@@ -68,6 +68,17 @@ class HttpService {
     return this.instance.put(url, data, config)
   }
 
+  public async patch<T>(
+    url: string,
+    data?: unknown,
+    config?: AxiosRequestConfig,
+  ): Promise<AxiosResponse<Result<T>>> {
+    return this.instance.patch(url, data, {
+      ...config,
+      headers: { 'Content-Type': 'application/merge-patch+json', ...config?.headers },
+    })
+  }
+
   public async delete<T>(
     url: string,
     config?: AxiosRequestConfig,
@@ -2,6 +2,17 @@ import axios from 'axios'
 import type {
   ArticleContent,
   ArticleSummary,
+  ArticleListQuery,
+  ArchiveYear,
+  ArticleImportResult,
+  TrashItem,
+  TrashType,
+  ArticleStatus,
+  PageResult,
+  RelatedArticle,
+  ArticleReactions,
+  ArticleAnalytics,
+  ReactionType,
   Tag,
   Comment,
   Result,
@@ -9,6 +20,7 @@ import type {
   OJProblem,
   OJTestCase,
   OJRequest,
+  MergePatch,
   SubmitResponse,
   JudgeResult,
   JudgeRequest,
@@ -37,6 +49,25 @@ async function request<T>(promise: Promise<AxiosResponse<Result<T>>>): Promise<R
   }
 }
 
+/**
+ * 生成携带If-Match的请求配置，版本与服务端不一致时返回412及最新内容
+ * @param version 获取内容时的版本号，不传表示不校验
+ */
+function ifMatch(version?: number) {
+  return version ? { headers: { 'If-Match': `"v${version}"` } } : undefined
+}
+
+function fetchArticlePage(
+  query: ArticleListQuery,
+  path: string = '/articles',
+): Promise<Result<PageResult<ArticleSummary>>> {
+  return request<PageResult<ArticleSummary>>(
+    http.get(path, {
+      params: { ...query, tagIds: query.tagIds?.length ? query.tagIds.join(',') : undefined },
+    }),
+  )
+}
+
 export const api = {
   // ===================== 标签(Tag)相关API =====================
   /**
@@ -56,16 +87,30 @@ export const api = {
    * 更新标签信息
    * @param id 要更新的标签ID
    * @param tag 包含新标签名称的对象 { name: string }
-   * @returns Promise<null> 更新成功返回null
+   * @param version 标签版本号，已被他人修改时返回412
+   * @returns Promise<Tag> 返回更新后的标签
+   */
+  updateTag: (id: number, tag: { name: string }, version?: number) =>
+    request<Tag>(http.put(`/tags/${id}`, tag, ifMatch(version))),
+
+  /**
+   * 部分修改标签 (JSON Merge Patch)
+   * @param id 要修改的标签ID
+   * @param patch 需要修改的字段，name不能为null
+   * @param version 标签版本号，已被他人修改时返回412
+   * @returns Promise<Tag> 返回修改后的标签
    */
-  updateTag: (id: number, tag: { name: string }) => request<null>(http.put(`/tags/${id}`, tag)),
+  patchTag: (id: number, patch: MergePatch<{ name: string }>, version?: number) =>
+    request<Tag>(http.patch(`/tags/${id}`, patch, ifMatch(version))),
 
   /**
    * 删除标签
    * @param id 要删除的标签ID
+   * @param version 标签版本号，已被他人修改时返回412
    * @returns Promise<null> 删除成功返回null
    */
-  deleteTag: (id: number) => request<null>(http.delete(`/tags/${id}`)),
+  deleteTag: (id: number, version?: number) =>
+    request<null>(http.delete(`/tags/${id}`, ifMatch(version))),
 
   // ===================== 文章(Article)相关API =====================
   /**
@@ -78,9 +123,11 @@ export const api = {
   /**
    * 删除文章
    * @param id 要删除的文章ID
+   * @param version 文章版本号，已被他人修改时返回412
    * @returns Promise<null> 删除成功返回null
    */
-  deleteArticle: (id: number) => request<null>(http.delete(`/articles/${id}`)),
+  deleteArticle: (id: number, version?: number) =>
+    request<null>(http.delete(`/articles/${id}`, ifMatch(version))),
 
   /**
    * 获取文章摘要列表 (支持分页)
@@ -88,17 +135,68 @@ export const api = {
    * @param pageSize 每页大小，默认为10
    * @returns Promise<ArticleSummary[]> 返回文章摘要数组
    */
-  getArticles: (page: number = 1, pageSize: number = 10) =>
-    request<ArticleSummary[]>(http.get(`/articles?page=${page}&pageSize=${pageSize}`)),
+  getArticles: async (page: number = 1, pageSize: number = 10): Promise<Result<ArticleSummary[]>> => {
+    const result = await fetchArticlePage({ page, pageSize })
+    return { ...result, data: result.data?.list }
+  },
+
+  /**
+   * 按条件获取文章分页列表 (服务端筛选、排序)
+   * @param query 筛选条件(ArticleListQuery类型)
+   * @returns Promise<PageResult<ArticleSummary>> 返回文章列表及总数
+   */
+  getArticlePage: (query: ArticleListQuery = {}) => fetchArticlePage(query),
+
+  /**
+   * 获取文章归档(按年、月统计的已发布文章数)
+   * @returns Promise<ArchiveYear[]> 返回按年份倒序的归档
+   */
+  getArticleArchive: () => request<ArchiveYear[]>(http.get('/articles/archive')),
+
+  /**
+   * 获取指定年份或月份发布的文章分页列表
+   * @param year 年份
+   * @param month 月份(1-12)，不传表示整年
+   * @param query 筛选条件(ArticleListQuery类型)
+   * @returns Promise<PageResult<ArticleSummary>> 返回文章列表及总数
+   */
+  getArchivedArticles: (year: number, month?: number, query: ArticleListQuery = {}) =>
+    fetchArticlePage(query, month ? `/articles/archive/${year}/${month}` : `/articles/archive/${year}`),
+
+  /**
+   * 管理端获取文章分页列表 (包含草稿、定时发布、归档文章)
+   * @param query 筛选条件(ArticleListQuery类型)，可附加status筛选
+   * @returns Promise<PageResult<ArticleSummary>> 返回文章列表及总数
+   */
+  getAdminArticlePage: (query: ArticleListQuery & { status?: ArticleStatus } = {}) =>
+    request<PageResult<ArticleSummary>>(
+      http.get('/admin/articles', {
+        params: { ...query, tagIds: query.tagIds?.length ? query.tagIds.join(',') : undefined },
+      }),
+    ),
 
   /**
    * 更新文章
    * @param id 文章ID
    * @param article 文章内容对象(ArticleRequest类型)
-   * @returns Promise<null> 更新成功返回null
+   * @param version 文章版本号，已被他人修改时返回412，data为最新内容
+   * @returns Promise<ArticleContent> 返回更新后的文章
    */
-  updateArticle: (id: number, article: ArticleRequest) =>
-    request<null>(http.put(`/articles/${id}`, article)),
+  updateArticle: (id: number, article: ArticleRequest, version?: number) =>
+    request<ArticleContent>(http.put(`/articles/${id}`, article, ifMatch(version))),
+
+  /**
+   * 部分修改文章 (JSON Merge Patch)
+   * @param id 文章ID
+   * @param patch 需要修改的字段；summary、coverUrl为null时恢复自动生成，为空字符串时清空
+   * @param version 文章版本号，已被他人修改时返回412，data为最新内容
+   * @returns Promise<ArticleContent> 返回修改后的文章
+   */
+  patchArticle: (
+    id: number,
+    patch: MergePatch<ArticleRequest & { status: ArticleStatus; publishedAt: string }>,
+    version?: number,
+  ) => request<ArticleContent>(http.patch(`/articles/${id}`, patch, ifMatch(version))),
 
   /**
    * 根据ID获取文章完整内容
@@ -107,6 +205,50 @@ export const api = {
    */
   getArticleById: (id: number) => request<ArticleContent>(http.get(`/articles/${id}`)),
 
+  /**
+   * 获取相关文章推荐
+   * @param id 文章ID
+   * @returns Promise<RelatedArticle[]> 返回按相关度排序的文章
+   */
+  getRelatedArticles: (id: number) =>
+    request<RelatedArticle[]>(http.get(`/articles/${id}/related`)),
+
+  /**
+   * 获取文章的点赞与回应状态
+   * @param id 文章ID
+   * @returns Promise<ArticleReactions> 返回各类回应计数及当前用户已做出的回应
+   */
+  getArticleReactions: (id: number) =>
+    request<ArticleReactions>(http.get(`/articles/${id}/reactions`)),
+
+  /**
+   * 点赞或做出回应（重复提交不会重复计数）
+   * @param id 文章ID
+   * @param type 回应类型
+   * @returns Promise<ArticleReactions> 返回最新的回应状态
+   */
+  reactToArticle: (id: number, type: ReactionType) =>
+    request<ArticleReactions>(http.put(`/articles/${id}/reactions/${type}`)),
+
+  /**
+   * 取消回应
+   * @param id 文章ID
+   * @param type 回应类型
+   * @returns Promise<ArticleReactions> 返回最新的回应状态
+   */
+  unreactToArticle: (id: number, type: ReactionType) =>
+    request<ArticleReactions>(http.delete(`/articles/${id}/reactions/${type}`)),
+
+  /**
+   * 获取文章的访问统计
+   * @param id 文章ID
+   * @param from 开始日期（YYYY-MM-DD），默认最近30天
+   * @param to 结束日期（YYYY-MM-DD），默认今天
+   * @returns Promise<ArticleAnalytics> 返回每日阅读量、独立访客与来源域名
+   */
+  getArticleAnalytics: (id: number, from?: string, to?: string) =>
+    request<ArticleAnalytics>(http.get(`/admin/articles/${id}/analytics`, { params: { from, to } })),
+
   // ===================== 评论(Comment)相关API =====================
   /**
    * 获取指定文章的所有评论
@@ -140,18 +282,31 @@ export const api = {
   /**
    * 删除OJ题目
    * @param id 要删除的题目ID
+   * @param version 题目版本号，已被他人修改时返回412
    * @returns Promise<null> 删除成功返回null
    */
-  deleteOJProblem: (id: number) => request<null>(http.delete(`/oj/problem/${id}`)),
+  deleteOJProblem: (id: number, version?: number) =>
+    request<null>(http.delete(`/oj/problem/${id}`, ifMatch(version))),
 
   /**
    * 更新OJ题目
    * @param id 题目ID
    * @param problem 题目内容对象(OJRequest类型)
-   * @returns Promise<null> 更新成功返回null
+   * @param version 题目版本号，已被他人修改时返回412，data为最新内容
+   * @returns Promise<OJProblem> 返回更新后的题目
+   */
+  putOJProblem: (id: number, problem: OJRequest, version?: number) =>
+    request<OJProblem>(http.put(`/oj/problem/${id}`, problem, ifMatch(version))),
+
+  /**
+   * 部分修改OJ题目 (JSON Merge Patch)
+   * @param id 题目ID
+   * @param patch 需要修改的字段；timeLimit、memoryLimit为null时恢复默认值
+   * @param version 题目版本号，已被他人修改时返回412，data为最新内容
+   * @returns Promise<OJProblem> 返回修改后的题目
    */
-  putOJProblem: (id: number, problem: OJRequest) =>
-    request<null>(http.put(`/oj/problem/${id}`, problem)),
+  patchOJProblem: (id: number, patch: MergePatch<OJRequest>, version?: number) =>
+    request<OJProblem>(http.patch(`/oj/problem/${id}`, patch, ifMatch(version))),
 
   /**
    * 为指定题目添加测试用例
@@ -176,6 +331,62 @@ export const api = {
   // 获取判题结果
   getJudgeResult: (token: string) => request<JudgeResult>(http.get(`/oj/judge?token=${token}`)),
 
+  // ===================== 文章导入导出API =====================
+  /**
+   * 从zip压缩包导入文章(Markdown + YAML元数据)
+   * @param file zip文件
+   * @returns Promise<ArticleImportResult> 返回导入结果
+   */
+  importArticles: (file: File) => {
+    const formData = new FormData()
+    formData.append('file', file)
+    return request<ArticleImportResult>(
+      http.post('/admin/articles/import', formData, {
+        headers: {
+          'Content-Type': 'multipart/form-data',
+        },
+        timeout: 120000,
+      }),
+    )
+  },
+
+  /**
+   * 文章导出的下载地址
+   * @param ids 要导出的文章ID，不传表示全部
+   * @returns string 下载地址
+   */
+  getArticleExportUrl: (ids?: number[]) =>
+    ids?.length ? `/api/admin/articles/export?ids=${ids.join(',')}` : '/api/admin/articles/export',
+
+  // ===================== 回收站相关API =====================
+  /**
+   * 获取回收站中指定类型的内容
+   * @param type 内容类型
+   * @param page 页码
+   * @param pageSize 每页数量
+   * @returns Promise<PageResult<TrashItem>> 返回按删除时间倒序的内容
+   */
+  getTrashItems: (type: TrashType, page: number = 1, pageSize: number = 20) =>
+    request<PageResult<TrashItem>>(http.get(`/admin/trash/${type}`, { params: { page, pageSize } })),
+
+  /**
+   * 从回收站恢复内容
+   * @param type 内容类型
+   * @param id 内容ID
+   * @returns Promise<null> 恢复成功返回null
+   */
+  restoreTrashItem: (type: TrashType, id: number) =>
+    request<null>(http.post(`/admin/trash/${type}/${id}/restore`)),
+
+  /**
+   * 永久删除回收站中的内容
+   * @param type 内容类型
+   * @param id 内容ID
+   * @returns Promise<null> 删除成功返回null
+   */
+  purgeTrashItem: (type: TrashType, id: number) =>
+    request<null>(http.delete(`/admin/trash/${type}/${id}`)),
+
   // ===================== 文件上传相关API =====================
   /**
    * 上传图片
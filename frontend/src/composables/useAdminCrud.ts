@@ -3,7 +3,7 @@ import { ref } from 'vue'
 interface CrudConfig<T extends { id: number }> {
   fetch: () => Promise<T[]>
   create: (item: Omit<T, 'id'>) => Promise<T>
-  delete: (id: number) => Promise<void>
+  delete: (id: number, item?: T) => Promise<void> // item为列表中的当前项，可取其版本号
   update?: (id: number, item: Partial<T>) => Promise<T>
 }
 
@@ -41,10 +41,12 @@ export function useAdminCrud<T extends { id: number }>(config: CrudConfig<T>) {
   const handleDelete = async (id: number) => {
     try {
       isLoading.value = true
-      await config.delete(id)
+      await config.delete(id, items.value.find(item => item.id === id) as T | undefined)
       await loadItems()
     } catch (error) {
-      errorMessage.value = error instanceof Error ? error.message : '删除失败'
+      errorMessage.value = error instanceof Error ? error.message : typeof error === 'string' ? error : '删除失败'
+      // 版本冲突(412)等失败后重新加载，下次删除使用最新版本号
+      await loadItems()
     } finally {
       isLoading.value = false
     }
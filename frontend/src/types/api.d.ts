@@ -7,28 +7,141 @@ export interface Result<T = unknown> {
 export interface ArticleContent {
   id: number
   title: string
+  slug?: string
   content: string
   summary?: string
   tagIds: number[]
   coverUrl?: string
   views: number // 添加阅读量字段
+  likes?: number
+  reactions?: Record<ReactionType, number>
+  wordCount?: number
+  readingTime?: number // 预计阅读时间（分钟）
+  version?: number // 版本号，更新时通过If-Match校验
   createdAt: string
   updatedAt: string
+  html?: string // 服务端渲染的HTML（include=html）
+  toc?: TocItem[] // 目录（include=toc）
+  series?: ArticleSeriesInfo
 }
 
+export interface SeriesNavItem {
+  id: number
+  title: string
+  slug: string
+}
+
+export interface ArticleSeriesInfo {
+  id: number
+  title: string
+  position: number
+  total: number
+  prev: SeriesNavItem | null
+  next: SeriesNavItem | null
+}
+
+export interface TocItem {
+  level: number
+  text: string
+  anchor: string
+  children?: TocItem[]
+}
+
+export type ArticleStatus = 'draft' | 'published' | 'scheduled' | 'archived'
+
 export interface ArticleSummary {
   id: number
   title: string
+  slug?: string
   summary: string
   tagIds: number[]
   views: number // 添加阅读量字段
+  likes?: number
+  reactions?: Record<ReactionType, number>
+  wordCount?: number
+  readingTime?: number // 预计阅读时间（分钟）
   createdAt: string
   coverUrl?: string
+  status?: ArticleStatus
+  publishedAt?: string
+  pinned?: boolean
+  pinOrder?: number
+  pinnedUntil?: string
+  featured?: boolean
+  version?: number // 版本号，更新时通过If-Match校验
+}
+
+export interface RelatedArticle extends ArticleSummary {
+  score: number
+}
+
+export type ReactionType = 'like' | 'love' | 'clap' | 'laugh' | 'confused'
+
+export interface ArticleReactions {
+  articleId: number
+  views: number // 实时阅读量
+  likes: number
+  reactions: Record<ReactionType, number>
+  mine: ReactionType[] // 当前用户已做出的回应
+}
+
+export interface ArticleAnalyticsDay {
+  date: string // YYYY-MM-DD
+  views: number
+  visitors: number // 当天独立访客数（估算值）
+}
+
+export interface ArticleAnalytics {
+  articleId: number
+  from: string
+  to: string
+  totalViews: number
+  daily: ArticleAnalyticsDay[] // 按日期升序，没有访问的日期为0
+  referrers: { domain: string; views: number }[] // 来源域名，direct表示直接访问
+}
+
+export interface PageResult<T> {
+  list: T[]
+  total: number
+  page: number
+  pageSize: number
+}
+
+export interface ArticleImportResult {
+  imported: number
+  articles: { file: string; id: number; title: string }[]
+  failed: { file: string; error: string }[]
+  tagsCreated: string[]
+  images: number
+}
+
+export interface ArchiveMonth {
+  month: number
+  count: number
+}
+
+export interface ArchiveYear {
+  year: number
+  count: number
+  months: ArchiveMonth[]
+}
+
+export interface ArticleListQuery {
+  page?: number
+  pageSize?: number
+  tagIds?: number[]
+  tagMode?: 'any' | 'all'
+  startDate?: string // YYYY-MM-DD
+  endDate?: string // YYYY-MM-DD
+  keyword?: string
+  sortBy?: 'createdAt' | 'updatedAt' | 'views' | 'likes'
+  order?: 'asc' | 'desc'
 }
 
 export interface Tag {
   id: number
   name: string
+  version?: number // 版本号，更新时通过If-Match校验
 }
 
 export interface Comment {
@@ -42,6 +155,7 @@ export interface Comment {
 
 export interface ArticleRequest {
   title: string
+  slug?: string
   content: string
   summary?: string
   tagIds: number[]
@@ -55,6 +169,7 @@ export interface OJProblem {
   difficulty: string // 新增：难度级别
   timeLimit: number // 新增：时间限制(ms)
   memoryLimit: number // 新增：内存限制(MB)
+  version?: number // 版本号，更新时通过If-Match校验
   createdAt: string // 新增：创建时间
   updatedAt: string // 新增：更新时间
 }
@@ -103,3 +218,18 @@ export interface MultiJudgeResult {
 }
 
 // Removed JudgeStatusMsg type because JudgeResult does not have a 'msg' property
+
+export type TrashType = 'articles' | 'comments' | 'tags' | 'problems'
+
+export interface TrashItem {
+  id: number
+  type: TrashType
+  title: string
+  deletedAt: string
+  purgeAt: string // 到期永久删除的时间
+}
+
+/**
+ * JSON Merge Patch请求体：未出现的字段保持不变，null表示清除（恢复默认）
+ */
+export type MergePatch<T> = { [K in keyof T]?: T[K] | null }